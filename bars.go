@@ -0,0 +1,220 @@
+package iss
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+/*
+Альтернативные бары: свеча закрывается не по времени, а по накопленному объему, обороту,
+количеству сделок или движению цены
+
+строятся из сделок (Tick) или из 5-минутных супер свечей TradeStats
+сделка и строка TradeStats не делятся между барами, поэтому бар может немного превышать заданный размер
+*/
+
+// BarKind тип альтернативного бара
+type BarKind int
+
+const (
+	VolumeBars BarKind = iota // по объему (лоты)
+	ValueBars                 // по обороту (рубли)
+	TickBars                  // по количеству сделок
+	RangeBars                 // по диапазону цены High - Low
+	RenkoBars                 // ренко: кирпичи фиксированного размера
+)
+
+// BarSpec параметры альтернативного бара
+type BarSpec struct {
+	Kind BarKind
+	Size float64
+}
+
+// VolumeBar бар закрывается, когда объем достиг size лотов
+func VolumeBar(size float64) BarSpec { return BarSpec{Kind: VolumeBars, Size: size} }
+
+// ValueBar бар закрывается, когда оборот достиг size рублей
+func ValueBar(size float64) BarSpec { return BarSpec{Kind: ValueBars, Size: size} }
+
+// TickBar бар закрывается после size сделок
+func TickBar(size int) BarSpec { return BarSpec{Kind: TickBars, Size: float64(size)} }
+
+// RangeBar бар закрывается, когда High - Low достиг size
+func RangeBar(size float64) BarSpec { return BarSpec{Kind: RangeBars, Size: size} }
+
+// RenkoBar кирпич размером size (разворот после движения на 2 * size)
+func RenkoBar(size float64) BarSpec { return BarSpec{Kind: RenkoBars, Size: size} }
+
+// String название для Candles.Interval: VOLUME:1000, RENKO:0.5
+func (b BarSpec) String() string {
+	names := map[BarKind]string{
+		VolumeBars: "VOLUME",
+		ValueBars:  "VALUE",
+		TickBars:   "TICK",
+		RangeBars:  "RANGE",
+		RenkoBars:  "RENKO",
+	}
+	return names[b.Kind] + ":" + strconv.FormatFloat(b.Size, 'f', -1, 64)
+}
+
+// Tick сделка для построения баров
+type Tick struct {
+	Time     time.Time
+	Price    float64
+	Quantity float64 // объем в лотах
+	Value    float64 // объем в рублях
+}
+
+// barUnit неделимая порция данных: одна сделка или одна строка TradeStats
+type barUnit struct {
+	begin, end             time.Time
+	open, high, low, close float64
+	volume, value, trades  float64
+}
+
+// BarBuilder потоковое построение альтернативных баров
+type BarBuilder struct {
+	spec    BarSpec
+	current *Candle
+	trades  float64 // сделок в текущем баре
+	// ренко: границы последнего кирпича
+	top, bottom float64
+	started     bool
+}
+
+// NewBarBuilder построитель баров по заданным параметрам
+func NewBarBuilder(spec BarSpec) (*BarBuilder, error) {
+	if spec.Size <= 0 || spec.Kind < VolumeBars || spec.Kind > RenkoBars {
+		return nil, fmt.Errorf("не верные параметры бара: %s", spec)
+	}
+	return &BarBuilder{spec: spec}, nil
+}
+
+// AddTick добавим сделку, вернем завершенные бары
+func (b *BarBuilder) AddTick(t Tick) []Candle {
+	return b.add(barUnit{
+		begin: t.Time, end: t.Time,
+		open: t.Price, high: t.Price, low: t.Price, close: t.Price,
+		volume: t.Quantity, value: t.Value, trades: 1,
+	})
+}
+
+// AddTradeStats добавим супер свечу (tradetime = окончание 5-минутного периода), вернем завершенные бары
+func (b *BarBuilder) AddTradeStats(s TradeStats) []Candle {
+	end, _ := ParseDate(s.TradeDate + " " + s.TradeTime)
+	return b.add(barUnit{
+		begin: end.Add(-5 * time.Minute), end: end.Add(-time.Second),
+		open: s.Open, high: s.High, low: s.Low, close: s.Close,
+		volume: float64(s.Volume), value: s.Value, trades: float64(s.Trades),
+	})
+}
+
+// Flush вернем незавершенный бар (false = его нет) и начнем новый
+func (b *BarBuilder) Flush() (Candle, bool) {
+	if b.current == nil {
+		return Candle{}, false
+	}
+	candle := *b.current
+	b.current, b.trades = nil, 0
+	return candle, true
+}
+
+func (b *BarBuilder) add(u barUnit) []Candle {
+	if b.spec.Kind == RenkoBars {
+		return b.addRenko(u)
+	}
+	if b.current == nil {
+		b.current = &Candle{Open: u.open, High: u.high, Low: u.low, Begin: u.begin.Format(layout)}
+	}
+	k := b.current
+	k.High = max(k.High, u.high)
+	k.Low = min(k.Low, u.low)
+	k.Close = u.close
+	k.Volume += u.volume
+	k.Value += u.value
+	k.End = u.end.Format(layout)
+	b.trades += u.trades
+
+	var done bool
+	switch b.spec.Kind {
+	case VolumeBars:
+		done = k.Volume >= b.spec.Size
+	case ValueBars:
+		done = k.Value >= b.spec.Size
+	case TickBars:
+		done = b.trades >= b.spec.Size
+	case RangeBars:
+		done = k.High-k.Low >= b.spec.Size
+	}
+	if !done {
+		return nil
+	}
+	candle, _ := b.Flush()
+	return []Candle{candle}
+}
+
+// addRenko кирпич вверх при цене >= верх последнего кирпича + size,
+// вниз при цене <= низ последнего кирпича - size
+// объем и оборот относятся к первому кирпичу, построенному после их накопления
+func (b *BarBuilder) addRenko(u barUnit) []Candle {
+	if !b.started {
+		b.top, b.bottom, b.started = u.close, u.close, true
+	}
+	if b.current == nil {
+		b.current = &Candle{Begin: u.begin.Format(layout)}
+	}
+	b.current.Volume += u.volume
+	b.current.Value += u.value
+	b.current.End = u.end.Format(layout)
+
+	size := b.spec.Size
+	var bricks []Candle
+	emit := func(open, close float64) {
+		k := *b.current
+		k.Open, k.Close = open, close
+		k.High, k.Low = max(open, close), min(open, close)
+		bricks = append(bricks, k)
+		b.current = &Candle{Begin: u.end.Format(layout), End: u.end.Format(layout)}
+	}
+	for u.close >= b.top+size {
+		emit(b.top, b.top+size)
+		b.bottom, b.top = b.top, b.top+size
+	}
+	for u.close <= b.bottom-size {
+		emit(b.bottom, b.bottom-size)
+		b.top, b.bottom = b.bottom, b.bottom-size
+	}
+	return bricks
+}
+
+// BuildBars построить альтернативные бары из сделок
+// последний незавершенный бар не включается
+func BuildBars(symbol string, ticks []Tick, spec BarSpec) (Candles, error) {
+	builder, err := NewBarBuilder(spec)
+	if err != nil {
+		return Candles{}, err
+	}
+	candles := Candles{Symbol: symbol, Interval: spec.String()}
+	for _, t := range ticks {
+		candles.Data = append(candles.Data, builder.AddTick(t)...)
+	}
+	return candles, nil
+}
+
+// BuildBarsFromTradeStats построить альтернативные бары из супер свечей TradeStats
+// последний незавершенный бар не включается
+func BuildBarsFromTradeStats(stats []TradeStats, spec BarSpec) (Candles, error) {
+	builder, err := NewBarBuilder(spec)
+	if err != nil {
+		return Candles{}, err
+	}
+	candles := Candles{Interval: spec.String()}
+	if len(stats) > 0 {
+		candles.Symbol = stats[0].SecID
+	}
+	for _, s := range stats {
+		candles.Data = append(candles.Data, builder.AddTradeStats(s)...)
+	}
+	return candles, nil
+}
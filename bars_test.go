@@ -0,0 +1,187 @@
+package iss
+
+import (
+	"testing"
+	"time"
+)
+
+// ticks сделки раз в секунду с 10:00:00: цена, лоты, оборот = цена * лоты
+func ticks(prices []float64, qty float64) []Tick {
+	start := time.Date(2024, 8, 6, 10, 0, 0, 0, TzMsk)
+	result := make([]Tick, len(prices))
+	for i, p := range prices {
+		result[i] = Tick{Time: start.Add(time.Duration(i) * time.Second), Price: p, Quantity: qty, Value: p * qty}
+	}
+	return result
+}
+
+func TestNewBarBuilder(t *testing.T) {
+	for _, spec := range []BarSpec{VolumeBar(0), ValueBar(-1), TickBar(0), RangeBar(0), RenkoBar(-0.5), {Kind: BarKind(10), Size: 1}} {
+		if _, err := NewBarBuilder(spec); err == nil {
+			t.Errorf("%s: без ошибки", spec)
+		}
+	}
+	if got := RenkoBar(0.5).String(); got != "RENKO:0.5" {
+		t.Errorf("String %s", got)
+	}
+	if got := VolumeBar(1000).String(); got != "VOLUME:1000" {
+		t.Errorf("String %s", got)
+	}
+}
+
+// бар закрывается на сделке, с которой накопленное значение достигло размера
+func TestBuildBarsThreshold(t *testing.T) {
+	type bar struct {
+		open, close, high, low, volume float64
+		begin, end                     string
+	}
+	prices := []float64{100, 101, 99, 102, 103, 98, 100}
+	tests := []struct {
+		name string
+		spec BarSpec
+		qty  float64
+		want []bar
+	}{
+		// 3 лота на сделку: 3, 6, 9 >= 9 = бар из 3 сделок
+		{"VolumeBar", VolumeBar(9), 3, []bar{
+			{100, 99, 101, 99, 9, "10:00:00", "10:00:02"},
+			{102, 98, 103, 98, 9, "10:00:03", "10:00:05"},
+		}},
+		// превышение размера не переносится в следующий бар
+		{"VolumeBar с превышением", VolumeBar(5), 3, []bar{
+			{100, 101, 101, 100, 6, "10:00:00", "10:00:01"},
+			{99, 102, 102, 99, 6, "10:00:02", "10:00:03"},
+			{103, 98, 103, 98, 6, "10:00:04", "10:00:05"},
+		}},
+		// оборот 100 + 101 = 201 < 300, + 99 = 300
+		{"ValueBar", ValueBar(300), 1, []bar{
+			{100, 99, 101, 99, 3, "10:00:00", "10:00:02"},
+			{102, 98, 103, 98, 3, "10:00:03", "10:00:05"},
+		}},
+		{"TickBar", TickBar(2), 1, []bar{
+			{100, 101, 101, 100, 2, "10:00:00", "10:00:01"},
+			{99, 102, 102, 99, 2, "10:00:02", "10:00:03"},
+			{103, 98, 103, 98, 2, "10:00:04", "10:00:05"},
+		}},
+		// диапазон 101 - 99 = 2 < 3, 102 - 99 = 3
+		{"RangeBar", RangeBar(3), 1, []bar{
+			{100, 102, 102, 99, 4, "10:00:00", "10:00:03"},
+			{103, 98, 103, 98, 2, "10:00:04", "10:00:05"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles, err := BuildBars("SBER", ticks(prices, tt.qty), tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if candles.Symbol != "SBER" || candles.Interval != tt.spec.String() {
+				t.Errorf("ряд %s %s", candles.Symbol, candles.Interval)
+			}
+			if len(candles.Data) != len(tt.want) {
+				t.Fatalf("баров %d, ожидали %d: %+v", len(candles.Data), len(tt.want), candles.Data)
+			}
+			for i, w := range tt.want {
+				k := candles.Data[i]
+				got := bar{k.Open, k.Close, k.High, k.Low, k.Volume, k.Begin[11:], k.End[11:]}
+				if got != w {
+					t.Errorf("бар %d: %+v, ожидали %+v", i, got, w)
+				}
+			}
+		})
+	}
+
+	// незавершенный бар = Flush
+	builder, _ := NewBarBuilder(TickBar(3))
+	for _, tick := range ticks(prices, 1) {
+		builder.AddTick(tick)
+	}
+	last, ok := builder.Flush()
+	if !ok || last.Open != 100 || last.Close != 100 || last.Volume != 1 {
+		t.Fatalf("Flush %+v %v", last, ok)
+	}
+	if _, ok = builder.Flush(); ok {
+		t.Fatal("повторный Flush")
+	}
+}
+
+func TestBuildBarsRenko(t *testing.T) {
+	type brick struct{ open, close float64 }
+	tests := []struct {
+		name   string
+		prices []float64
+		want   []brick
+	}{
+		{"рост на размер", []float64{100, 100.5, 101}, []brick{{100, 101}}},
+		{"гэп на несколько кирпичей", []float64{100, 103.5}, []brick{{100, 101}, {101, 102}, {102, 103}}},
+		// после кирпича 100 -> 101 разворот вниз только при цене <= 99 (2 * size от верха)
+		{"разворот после 2 * size", []float64{100, 101, 100, 99.5, 99}, []brick{{100, 101}, {100, 99}}},
+		{"продолжение без разворота", []float64{100, 101, 100.2, 102}, []brick{{100, 101}, {101, 102}}},
+		{"падение", []float64{100, 99, 98.5, 98}, []brick{{100, 99}, {99, 98}}},
+		{"разворот вверх", []float64{100, 99, 100, 101}, []brick{{100, 99}, {100, 101}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles, err := BuildBars("SBER", ticks(tt.prices, 1), RenkoBar(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(candles.Data) != len(tt.want) {
+				t.Fatalf("кирпичей %d, ожидали %d: %+v", len(candles.Data), len(tt.want), candles.Data)
+			}
+			for i, w := range tt.want {
+				k := candles.Data[i]
+				if (brick{k.Open, k.Close}) != w || k.High != max(w.open, w.close) || k.Low != min(w.open, w.close) {
+					t.Errorf("кирпич %d: %+v, ожидали %+v", i, k, w)
+				}
+			}
+		})
+	}
+
+	// объем относится к первому кирпичу после накопления, следующие кирпичи той же сделки без объема
+	candles, _ := BuildBars("SBER", ticks([]float64{100, 100.5, 103}, 2), RenkoBar(1))
+	volumes := []float64{6, 0, 0}
+	for i, k := range candles.Data {
+		if k.Volume != volumes[i] {
+			t.Errorf("объем кирпича %d = %v, ожидали %v", i, k.Volume, volumes[i])
+		}
+	}
+	if candles.Data[0].Begin != "2024-08-06 10:00:00" || candles.Data[1].Begin != "2024-08-06 10:00:02" {
+		t.Errorf("время кирпичей %s %s", candles.Data[0].Begin, candles.Data[1].Begin)
+	}
+}
+
+// строка TradeStats = 5 минут, tradetime = окончание периода
+func TestBuildBarsFromTradeStats(t *testing.T) {
+	stats := []TradeStats{
+		{SecID: "SBER", TradeDate: "2024-08-06", TradeTime: "10:05:00", Open: 100, High: 102, Low: 99, Close: 101, Volume: 400, Value: 4e6, Trades: 10},
+		{SecID: "SBER", TradeDate: "2024-08-06", TradeTime: "10:10:00", Open: 101, High: 103, Low: 100, Close: 102, Volume: 700, Value: 7e6, Trades: 20},
+		{SecID: "SBER", TradeDate: "2024-08-06", TradeTime: "10:15:00", Open: 102, High: 104, Low: 101, Close: 103, Volume: 500, Value: 5e6, Trades: 5},
+	}
+	candles, err := BuildBarsFromTradeStats(stats, VolumeBar(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if candles.Symbol != "SBER" || len(candles.Data) != 1 {
+		t.Fatalf("бары %+v", candles)
+	}
+	k := candles.Data[0]
+	if k.Begin != "2024-08-06 10:00:00" || k.End != "2024-08-06 10:09:59" {
+		t.Errorf("время бара %s - %s", k.Begin, k.End)
+	}
+	if k.Open != 100 || k.High != 103 || k.Low != 99 || k.Close != 102 || k.Volume != 1100 || k.Value != 11e6 {
+		t.Errorf("бар %+v", k)
+	}
+
+	// по количеству сделок из TRADES
+	candles, _ = BuildBarsFromTradeStats(stats, TickBar(30))
+	if len(candles.Data) != 1 || candles.Data[0].End != "2024-08-06 10:09:59" {
+		t.Errorf("TickBar %+v", candles.Data)
+	}
+	// ренко по Close: следующий кирпич начинается с окончания строки, закрывшей предыдущий
+	candles, _ = BuildBarsFromTradeStats(stats, RenkoBar(1))
+	if len(candles.Data) != 2 || candles.Data[0].Begin != "2024-08-06 10:00:00" || candles.Data[0].End != "2024-08-06 10:09:59" ||
+		candles.Data[1].Begin != "2024-08-06 10:09:59" || candles.Data[1].End != "2024-08-06 10:14:59" {
+		t.Errorf("RenkoBar %+v", candles.Data)
+	}
+}
@@ -63,9 +63,9 @@ func (c *Client) GetBondsInfo(board string) ([]BondInfo, error) {
 	}
 
 	var resp Response
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
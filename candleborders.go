@@ -0,0 +1,75 @@
+package iss
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// https://iss.moex.com/iss/engines/stock/markets/shares/boards/TQBR/securities/SBER/candleborders.json
+
+// CandleBorder границы доступной истории свечей по одному интервалу
+type CandleBorder struct {
+	Begin        string   `json:"begin"`    // время начала первой свечи
+	End          string   `json:"end"`      // время окончания последней свечи
+	Interval     Interval `json:"interval"` // интервал свечей
+	BoardGroupId int      `json:"board_group_id"`
+}
+
+// BeginTime время начала первой свечи (по московскому времени)
+func (b CandleBorder) BeginTime() time.Time {
+	t, _ := ParseDate(b.Begin)
+	return t
+}
+
+// EndTime время окончания последней свечи (по московскому времени)
+func (b CandleBorder) EndTime() time.Time {
+	t, _ := ParseDate(b.End)
+	return t
+}
+
+// CandleBorders границы истории свечей по всем интервалам
+type CandleBorders []CandleBorder
+
+// Get границы истории по заданному интервалу
+func (b CandleBorders) Get(interval Interval) (CandleBorder, bool) {
+	for _, border := range b {
+		if border.Interval == interval {
+			return border, true
+		}
+	}
+	return CandleBorder{}, false
+}
+
+// GetCandleBorders получить границы доступной истории свечей по инструменту
+func (c *Client) GetCandleBorders(engines, markets, board, symbol string) (CandleBorders, error) {
+	var err error
+	const op = "GetCandleBorders"
+
+	r := &request{
+		method: http.MethodGet,
+		fullURL: NewIssRequest().Candle().
+			Engines(engines).
+			Markets(markets).
+			Boards(board).
+			Symbol(symbol).
+			Target("candleborders").
+			Json().MetaData(false).URL(),
+	}
+
+	var resp Response
+	err = c.getData(r, &resp)
+	if err != nil {
+		slog.Error(op+".getData", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make(CandleBorders, 0, len(resp.Borders.Data))
+	err = Unmarshal(resp.Borders.Columns, resp.Borders.Data, &result)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
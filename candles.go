@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -22,10 +23,11 @@ type Candle struct {
 
 var layout = "2006-01-02 15:04:05"
 
-// Time время начала свечи
+// Time время начала свечи (по московскому времени)
+// раньше строка begin разбиралась как UTC (см. README, несовместимые изменения)
 func (k Candle) Time() time.Time {
 	var t time.Time
-	t, err := time.Parse(layout, k.Begin)
+	t, err := time.ParseInLocation(layout, k.Begin, TzMsk)
 	if err != nil {
 		slog.Error("Time", "err", err.Error())
 	}
@@ -59,69 +61,25 @@ func (k Candles) Index(position int) Candle {
 	return k.Last(position)
 }
 
-// Доступные интервалы свечей.
-const (
-	Interval_M1  = 1
-	Interval_M10 = 10
-	Interval_H1  = 60
-	Interval_D1  = 24
-	Interval_W1  = 7
-	Interval_MN1 = 31
-	Interval_Q1  = 4
-)
-
-// ParseInterval
-func ParseInterval(input string) (int, error) {
-	switch input {
-	case "M1":
-		return Interval_M1, nil
-	case "M10":
-		return Interval_M10, nil
-	case "H1":
-		return Interval_H1, nil
-	case "D1":
-		return Interval_D1, nil
-	case "W1":
-		return Interval_W1, nil
-	case "MN1":
-		return Interval_MN1, nil
-	case "Q1":
-		return Interval_Q1, nil
-	default:
-		return -1, fmt.Errorf("не поддерживаемый формат периода свечи %s", input)
-	}
-}
-
-// IntervalToString конвертация Interval свечей  в строку
-func IntervalToString(i int) string {
-	switch i {
-	case Interval_M1:
-		return "M1"
-	case Interval_M10:
-		return "M10"
-	case Interval_H1:
-		return "H1"
-	case Interval_D1:
-		return "D1"
-	case Interval_W1:
-		return "W1"
-	case Interval_MN1:
-		return "MN1"
-	case Interval_Q1:
-		return "Q1"
-
-	}
-	return "неизвестно"
-}
-
 // CandlesService сервис для получения исторических свечей
+// блока candles.cursor сервер не присылает, поэтому общее количество неизвестно
+// ClampToBorders = пустые from/till заменяются границами доступной истории (candleborders)
+// Next можно вызывать из нескольких горутин (страницы выдаются по очереди),
+// Do каждый раз выгружает данные с начала и не меняет состояние сервиса
 type CandlesService struct {
 	client     *Client
-	issRequest *IssRequest
+	issRequest *IssRequest   // не изменяемый
+	progress   ProgressFunc  // прогресс выгрузки
+	timeframe  *Timeframe    // пересчитать свечи в таймфрейм (nil = как есть)
+	sessions   []Session     // сессии для пересчета
+	borders    *bordersCache // границы истории для ClampToBorders (nil = не заменять пустые from/till)
+	clamped    *IssRequest   // запрос с from/till по границам истории (nil = еще не проверяли)
+	mu         sync.Mutex
+	state      pageState // состояние постраничной выгрузки
 }
 
 // NewCandlesService создание сервиса
-func (c *Client) NewCandlesService(engines, markets, board, symbol string, interval int, from, to string) *CandlesService {
+func (c *Client) NewCandlesService(engines, markets, board, symbol string, interval Interval, from, to string) *CandlesService {
 	iss := NewIssRequest().Candle().
 		Engines(engines).
 		Markets(markets).
@@ -138,13 +96,70 @@ func (c *Client) NewCandlesService(engines, markets, board, symbol string, inter
 	}
 }
 
+// Csv запрашивать данные в формате csv (ответ меньше по размеру чем json)
+func (s *CandlesService) Csv() *CandlesService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issRequest = s.issRequest.Csv()
+	return s
+}
+
+// Progress установим функцию обратного вызова для отображения прогресса выгрузки
+// total всегда = 0
+func (s *CandlesService) Progress(fn ProgressFunc) *CandlesService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress = fn
+	return s
+}
+
+// Timeframe Do вернет свечи, пересчитанные в заданный таймфрейм
+// загружается самый крупный подходящий интервал iss (BaseInterval), Next возвращает свечи этого интервала
+func (s *CandlesService) Timeframe(tf Timeframe, sessions ...Session) *CandlesService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	base, err := tf.BaseInterval(sessions...)
+	if err != nil {
+		// ошибку вернет Next через проверку интервала
+		base = 0
+	}
+	s.issRequest = s.issRequest.Interval(base)
+	s.timeframe = &tf
+	s.sessions = sessions
+	return s
+}
+
+// Reset начать выгрузку заново
+func (s *CandlesService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = pageState{}
+	s.clamped = nil
+}
+
+// Clone независимая копия сервиса с начальным состоянием выгрузки
+func (s *CandlesService) Clone() *CandlesService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &CandlesService{
+		client:     s.client,
+		issRequest: s.issRequest,
+		progress:   s.progress,
+		timeframe:  s.timeframe,
+		sessions:   s.sessions,
+		borders:    s.borders,
+	}
+}
+
 // Do выполняет выгрузку свечей
 func (s *CandlesService) Do() (Candles, error) {
 	const op = "CandlesService.Do"
 
+	// отдельная копия = Do не мешает вызовам Next и другим Do
+	it := s.Clone()
 	candles := Candles{
-		Symbol:   s.issRequest.symbol,
-		Interval: IntervalToString(s.issRequest.interval),
+		Symbol:   it.issRequest.symbol,
+		Interval: it.issRequest.interval.String(),
 	}
 	candlesData := make([]Candle, 0)
 	count := 1
@@ -152,7 +167,7 @@ func (s *CandlesService) Do() (Candles, error) {
 		// "fetch candles: item(s) processed"
 		s.client.log.Debug(op, "запрос свечей: номер запроса", count)
 
-		t_candles, err := s.Next()
+		t_candles, err := it.Next()
 		if err != nil {
 			if errors.Is(err, EOF) {
 				break
@@ -164,6 +179,9 @@ func (s *CandlesService) Do() (Candles, error) {
 
 	}
 	candles.Data = candlesData
+	if it.timeframe != nil {
+		return candles.Resample(*it.timeframe, it.sessions...)
+	}
 	return candles, nil
 }
 
@@ -174,15 +192,39 @@ func (s *CandlesService) Next() ([]Candle, error) {
 	var err error
 	const op = "CandlesService.Next"
 
+	// прогресс сообщаем после s.mu.Unlock (defer выполняются в обратном порядке):
+	// из ProgressFunc можно вызывать методы сервиса
+	var notify func()
+	defer func() {
+		if notify != nil {
+			notify()
+		}
+	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.issRequest.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !s.issRequest.interval.IsValid() {
+		return nil, fmt.Errorf("%s: %w: %d", op, ErrInterval, int(s.issRequest.interval))
+	}
+
+	if s.clamped == nil {
+		if s.clamped, err = s.clampToBorders(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	r := &request{
 		method:  http.MethodGet,
-		fullURL: s.issRequest.URL(),
+		fullURL: s.clamped.Start(s.state.start).URL(),
 	}
 
 	var resp Response
-	err = s.client.getJSON(r, &resp)
+	err = s.client.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -202,23 +244,90 @@ func (s *CandlesService) Next() ([]Candle, error) {
 		"maxdate", result[len(result)-1].Begin,
 	)
 
-	// увеличим параметр start на кол-во полученных данных
-	s.issRequest.start += len(result)
-	s.client.log.Debug(op, "s.issRequest.start", s.issRequest.start)
+	s.state.update(len(result), Cursor{}, false)
+	s.client.log.Debug(op, "start", s.state.start)
+	if progress := s.progress; progress != nil {
+		fetched, total := s.state.fetched, 0
+		notify = func() { progress(fetched, total) }
+	}
 
 	return result, nil
 }
 
+// ClampToBorders заменять пустые from/till границами доступной истории свечей
+// границы запрашиваются один раз на сервис (общие для Clone и Do)
+func (s *CandlesService) ClampToBorders() *CandlesService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.borders == nil {
+		s.borders = &bordersCache{}
+	}
+	return s
+}
+
+// bordersCache границы истории свечей, общие для сервиса и его копий
+type bordersCache struct {
+	mu      sync.Mutex
+	borders CandleBorders // nil = еще не запрашивали
+}
+
+// get границы истории: запрос к серверу только в первый раз (после ошибки = повторный запрос)
+func (b *bordersCache) get(c *Client, u *IssRequest) (CandleBorders, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.borders != nil {
+		return b.borders, nil
+	}
+	borders, err := c.GetCandleBorders(u.engines, u.markets, u.boards, u.symbol)
+	if err != nil {
+		return nil, err
+	}
+	b.borders = borders
+	return borders, nil
+}
+
+// clampToBorders заменим пустые from/till границами доступной истории свечей (если задан ClampToBorders)
+func (s *CandlesService) clampToBorders() (*IssRequest, error) {
+	u := s.issRequest
+	if s.borders == nil || (u.dateFrom != "" && u.dateTo != "") {
+		return u, nil
+	}
+	borders, err := s.borders.get(s.client, u)
+	if err != nil {
+		return nil, err
+	}
+	border, ok := borders.Get(u.interval)
+	if !ok {
+		// по интервалу нет истории = запрос как есть
+		return u, nil
+	}
+	if u.dateFrom == "" {
+		u = u.From(border.Begin)
+	}
+	if u.dateTo == "" {
+		u = u.To(border.End)
+	}
+	return u, nil
+}
+
 // GetStockCandles получить историю свечей по акциям
 // Board TQBR
-func (c *Client) GetStockCandles(symbols string, interval int, from, to string) (Candles, error) {
-	return c.NewCandlesService("stock", "shares", StockBoard, symbols, int(interval), from, to).Do()
+func (c *Client) GetStockCandles(symbols string, interval Interval, from, to time.Time) (Candles, error) {
+	return c.GetMarketCandles(MarketShares, symbols, interval, from, to)
 
 }
 
 // GetFortsCandles получить историю свечей по акциям
 // Board RFUD
-func (c *Client) GetFortsCandles(symbols string, interval int, from, to string) (Candles, error) {
-	return c.NewCandlesService("futures", "forts", FortsBoard, symbols, int(interval), from, to).Do()
+func (c *Client) GetFortsCandles(symbols string, interval Interval, from, to time.Time) (Candles, error) {
+	return c.GetMarketCandles(MarketFutures, symbols, interval, from, to)
+
+}
 
+// GetCandles получить историю свечей в произвольном таймфрейме (M5 M15 M30 H4 ...)
+// загружается самый крупный подходящий интервал iss и пересчитывается в таймфрейм
+func (c *Client) GetCandles(engines, markets, board, symbol string, tf Timeframe, from, to time.Time, sessions ...Session) (Candles, error) {
+	s := c.NewCandlesService(engines, markets, board, symbol, 0, "", "").Timeframe(tf, sessions...)
+	s.issRequest = s.issRequest.DateRange(from, to)
+	return s.Do()
 }
@@ -0,0 +1,86 @@
+package iss
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	sberCandles = "engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json"
+	sberBorders = "engines/stock/markets/shares/boards/TQBR/securities/SBER/candleborders.json"
+)
+
+func TestCandlesWithoutClamp(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	service := newFakeClient(t, f).NewCandlesService("stock", "shares", StockBoard, "SBER", Interval_D1, "", "")
+	for i := 0; i < 3; i++ {
+		candles, err := service.Do()
+		if err != nil || candles.Len() != 6 {
+			t.Fatalf("Do: %d свечей, %v", candles.Len(), err)
+		}
+	}
+	if n := f.count(sberBorders); n != 0 {
+		t.Fatalf("запросов candleborders: %d, ожидали 0", n)
+	}
+	q, _ := f.last(sberCandles)
+	if q.Has("from") || q.Has("till") {
+		t.Fatalf("параметры запроса: %v", q)
+	}
+}
+
+func TestCandlesClampToBorders(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	service := newFakeClient(t, f).NewCandlesService("stock", "shares", StockBoard, "SBER", Interval_D1, "", "").ClampToBorders()
+	for i := 0; i < 3; i++ {
+		if _, err := service.Do(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	service.Reset()
+	if _, err := service.Next(); err != nil {
+		t.Fatal(err)
+	}
+	// границы запрашиваются один раз на сервис (Do работает с копией)
+	if n := f.count(sberBorders); n != 1 {
+		t.Fatalf("запросов candleborders: %d, ожидали 1", n)
+	}
+	q, _ := f.last(sberCandles)
+	if q.Get("from") != "2011-12-15 00:00:00" || q.Get("till") != "2024-08-06 00:00:00" {
+		t.Fatalf("параметры запроса: %v", q)
+	}
+
+	// заданные from/till не заменяются
+	service = newFakeClient(t, f).NewCandlesService("stock", "shares", StockBoard, "SBER", Interval_D1, "2024-08-01", "").ClampToBorders()
+	if _, err := service.Do(); err != nil {
+		t.Fatal(err)
+	}
+	q, _ = f.last(sberCandles)
+	if q.Get("from") != "2024-08-01" || q.Get("till") != "2024-08-06 00:00:00" {
+		t.Fatalf("параметры запроса: %v", q)
+	}
+}
+
+func TestTickerCandlesBorders(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	ticker, err := newFakeClient(t, f).GetTicker("SBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// from задан = границы не нужны
+	if _, err = ticker.Candles(Interval_D1, time.Date(2024, 8, 1, 0, 0, 0, 0, TzMsk), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.count(sberBorders); n != 0 {
+		t.Fatalf("запросов candleborders: %d, ожидали 0", n)
+	}
+	// пустой from = вся доступная история
+	if _, err = ticker.Candles(Interval_D1, time.Time{}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.count(sberBorders); n != 1 {
+		t.Fatalf("запросов candleborders: %d, ожидали 1", n)
+	}
+}
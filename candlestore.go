@@ -0,0 +1,303 @@
+package iss
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CandleStore хранилище свечей для инкрементальной загрузки (SyncCandles)
+// свечи по символу и интервалу хранятся по возрастанию времени начала
+type CandleStore interface {
+	// Last последняя сохраненная свеча (false = свечей нет)
+	Last(symbol string, interval Interval) (Candle, bool, error)
+	// Append добавить свечи (по возрастанию Begin)
+	// сохраненные свечи с Begin не раньше первой добавляемой заменяются
+	Append(symbol string, interval Interval, candles []Candle) error
+	// Range свечи с началом в диапазоне [from, to] (пустое время = без ограничения)
+	Range(symbol string, interval Interval, from, to time.Time) ([]Candle, error)
+}
+
+// FileCandleStore хранилище свечей в файлах: <dir>/<symbol>_<interval>.csv
+// строка файла: begin,end,open,close,high,low,value,volume
+// запись идет в конец файла, заменяемые свечи отрезаются с конца (файл целиком не перечитывается)
+type FileCandleStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileCandleStore хранилище в заданной директории (создается при необходимости)
+func NewFileCandleStore(dir string) (*FileCandleStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCandleStore{dir: dir}, nil
+}
+
+// fileName файл с данными по символу и интервалу
+func (s *FileCandleStore) fileName(symbol string, interval Interval) string {
+	return filepath.Join(s.dir, symbol+"_"+interval.String()+".csv")
+}
+
+// Last последняя сохраненная свеча
+func (s *FileCandleStore) Last(symbol string, interval Interval) (Candle, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.fileName(symbol, interval))
+	if errors.Is(err, os.ErrNotExist) {
+		return Candle{}, false, nil
+	}
+	if err != nil {
+		return Candle{}, false, err
+	}
+	defer f.Close()
+
+	var last string
+	_, err = scanBack(f, func(line string) bool {
+		last = line
+		return false
+	})
+	if err != nil || last == "" {
+		return Candle{}, false, err
+	}
+	candle, err := parseCandleLine(last)
+	if err != nil {
+		return Candle{}, false, err
+	}
+	return candle, true, nil
+}
+
+// Append добавить свечи, сохраненные свечи с Begin не раньше первой добавляемой заменяются
+func (s *FileCandleStore) Append(symbol string, interval Interval, candles []Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.fileName(symbol, interval), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// отрежем свечи, которые будут заменены
+	begin := candles[0].Begin
+	offset, err := scanBack(f, func(line string) bool {
+		return candleLineBegin(line) >= begin
+	})
+	if err != nil {
+		return err
+	}
+	if err = f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, candle := range candles {
+		w.WriteString(formatCandleLine(candle))
+		w.WriteByte('\n')
+	}
+	return w.Flush()
+}
+
+// Range свечи с началом в диапазоне [from, to]
+func (s *FileCandleStore) Range(symbol string, interval Interval, from, to time.Time) ([]Candle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.fileName(symbol, interval))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := make([]Candle, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		candle, err := parseCandleLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		t := candle.Time()
+		if !from.IsZero() && t.Before(from) {
+			continue
+		}
+		if !to.IsZero() && t.After(to) {
+			break
+		}
+		result = append(result, candle)
+	}
+	return result, scanner.Err()
+}
+
+// scanBack читает строки файла с конца, пока fn возвращает true
+// вернем смещение начала последней принятой строки (размер файла = ни одной)
+func scanBack(f *os.File, fn func(line string) bool) (int64, error) {
+	const blockSize = 64 * 1024
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	cut := info.Size() // все после cut принято
+	pos := cut         // tail = содержимое файла [pos:cut]
+	var tail []byte
+	for cut > 0 {
+		data := bytes.TrimSuffix(tail, []byte{'\n'})
+		i := bytes.LastIndexByte(data, '\n')
+		if i < 0 && pos > 0 {
+			// строка целиком не прочитана, дочитаем блок
+			n := min(int64(blockSize), pos)
+			block := make([]byte, n, n+int64(len(tail)))
+			if _, err = f.ReadAt(block, pos-n); err != nil {
+				return 0, err
+			}
+			tail = append(block, tail...)
+			pos -= n
+			continue
+		}
+		lineStart := pos + int64(i+1)
+		if len(data) > 0 && !fn(string(data[i+1:])) {
+			return cut, nil
+		}
+		cut = lineStart
+		tail = tail[:lineStart-pos]
+	}
+	return cut, nil
+}
+
+// formatCandleLine свеча в строку файла
+func formatCandleLine(k Candle) string {
+	values := []string{k.Begin, k.End}
+	for _, v := range []float64{k.Open, k.Close, k.High, k.Low, k.Value, k.Volume} {
+		values = append(values, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return strings.Join(values, ",")
+}
+
+// candleLineBegin время начала свечи из строки файла (без разбора всей строки)
+func candleLineBegin(line string) string {
+	begin, _, _ := strings.Cut(line, ",")
+	return begin
+}
+
+// parseCandleLine строка файла в свечу
+func parseCandleLine(line string) (Candle, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) != 8 {
+		return Candle{}, fmt.Errorf("не верная строка свечи: %s", line)
+	}
+	values := make([]float64, 6)
+	for i, field := range fields[2:] {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return Candle{}, fmt.Errorf("не верная строка свечи: %s: %w", line, err)
+		}
+		values[i] = v
+	}
+	return Candle{
+		Begin:  fields[0],
+		End:    fields[1],
+		Open:   values[0],
+		Close:  values[1],
+		High:   values[2],
+		Low:    values[3],
+		Value:  values[4],
+		Volume: values[5],
+	}, nil
+}
+
+// syncWorkers сколько символов SyncCandles загружает одновременно
+const syncWorkers = 4
+
+// SyncCandles догрузить свечи по тикерам в хранилище
+// загрузка продолжается с последней сохраненной свечи: она могла быть еще не сформирована и перезаписывается,
+// свечи, которые начались раньше нее (сервер отдает свечи, пересекающиеся с from), отбрасываются
+// пустое хранилище = вся доступная история (candleborders)
+// символы загружаются параллельно, ошибки по символам объединяются
+func SyncCandles(ctx context.Context, store CandleStore, tickers []*Ticker, interval Interval) error {
+	sem := make(chan struct{}, syncWorkers)
+	errs := make([]error, len(tickers))
+	var wg sync.WaitGroup
+	for i, ticker := range tickers {
+		wg.Add(1)
+		go func(i int, ticker *Ticker) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("%s: %w", ticker.SecID, ctx.Err())
+				return
+			}
+			if err := syncTickerCandles(ctx, store, ticker, interval); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", ticker.SecID, err)
+			}
+		}(i, ticker)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// syncTickerCandles догрузить свечи по одному тикеру
+func syncTickerCandles(ctx context.Context, store CandleStore, t *Ticker, interval Interval) error {
+	const op = "SyncCandles"
+
+	last, ok, err := store.Last(t.SecID, interval)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	s := t.client.NewCandlesService(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol, interval, "", "")
+	if ok {
+		s.issRequest = s.issRequest.FromTime(last.Time())
+	} else {
+		// пустое хранилище = с начала доступной истории
+		s.ClampToBorders()
+	}
+	count := 0
+	for {
+		if err = ctx.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		candles, err := s.Next()
+		if err != nil {
+			if errors.Is(err, EOF) {
+				break
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if ok {
+			// отбросим свечи, начавшиеся до последней сохраненной
+			first := 0
+			for first < len(candles) && candles[first].Begin < last.Begin {
+				first++
+			}
+			candles = candles[first:]
+		}
+		if err = store.Append(t.SecID, interval, candles); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		count += len(candles)
+	}
+	t.client.log.Debug(op, "symbol", t.SecID, "interval", interval.String(), "count", count)
+	return nil
+}
@@ -0,0 +1,254 @@
+package iss
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dayCandle дневная свеча 2024-08-<day>
+func dayCandle(day int, close float64) Candle {
+	date := fmt.Sprintf("2024-08-%02d", day)
+	return Candle{Begin: date + " 00:00:00", End: date + " 23:59:59", Open: 300, Close: close, High: 310, Low: 290, Value: 1.5e9, Volume: 5e6}
+}
+
+// storedBegins Begin сохраненных свечей через запятую
+func storedBegins(t *testing.T, store CandleStore, symbol string) string {
+	t.Helper()
+	candles, err := store.Range(symbol, Interval_D1, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	days := make([]string, 0, len(candles))
+	for _, c := range candles {
+		days = append(days, c.Begin[8:10])
+	}
+	return strings.Join(days, ",")
+}
+
+func TestFileCandleStore(t *testing.T) {
+	store, err := NewFileCandleStore(filepath.Join(t.TempDir(), "candles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// пустое хранилище (файла нет)
+	if _, ok, err := store.Last("SBER", Interval_D1); ok || err != nil {
+		t.Fatalf("Last без файла: %v %v", ok, err)
+	}
+	if candles, err := store.Range("SBER", Interval_D1, time.Time{}, time.Time{}); len(candles) != 0 || err != nil {
+		t.Fatalf("Range без файла: %v %v", candles, err)
+	}
+	// пустой файл
+	if err = os.WriteFile(store.fileName("SBER", Interval_D1), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := store.Last("SBER", Interval_D1); ok || err != nil {
+		t.Fatalf("Last пустого файла: %v %v", ok, err)
+	}
+	if err = store.Append("SBER", Interval_D1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// запись и чтение без потерь
+	in := []Candle{dayCandle(1, 301.5), dayCandle(2, 302.25), dayCandle(5, 305)}
+	in[1].Value = 123456789.125
+	if err = store.Append("SBER", Interval_D1, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := store.Range("SBER", Interval_D1, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("свечей %d, ожидали %d", len(out), len(in))
+	}
+	for i := range in {
+		if out[i] != in[i] {
+			t.Errorf("свеча %d: %+v, ожидали %+v", i, out[i], in[i])
+		}
+	}
+	last, ok, err := store.Last("SBER", Interval_D1)
+	if err != nil || !ok || last != in[2] {
+		t.Fatalf("Last %+v %v %v", last, ok, err)
+	}
+
+	// Range по времени начала [from, to]
+	from := time.Date(2024, 8, 2, 0, 0, 0, 0, TzMsk)
+	if got, _ := store.Range("SBER", Interval_D1, from, from.AddDate(0, 0, 2)); len(got) != 1 || got[0] != in[1] {
+		t.Errorf("Range: %+v", got)
+	}
+
+	// пересечение: свечи с Begin не раньше первой новой заменяются
+	if err = store.Append("SBER", Interval_D1, []Candle{dayCandle(2, 400), dayCandle(3, 401)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedBegins(t, store, "SBER"); got != "01,02,03" {
+		t.Fatalf("после замены: %s", got)
+	}
+	if got, _ := store.Range("SBER", Interval_D1, from, from); len(got) != 1 || got[0].Close != 400 {
+		t.Fatalf("замененная свеча: %+v", got)
+	}
+	// новая свеча после последней = дописывается, замена всех = файл с начала
+	if err = store.Append("SBER", Interval_D1, []Candle{dayCandle(4, 402)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedBegins(t, store, "SBER"); got != "01,02,03,04" {
+		t.Fatalf("после добавления: %s", got)
+	}
+	if err = store.Append("SBER", Interval_D1, []Candle{dayCandle(1, 500)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedBegins(t, store, "SBER"); got != "01" {
+		t.Fatalf("после замены всех: %s", got)
+	}
+
+	// символы и интервалы в разных файлах
+	if _, ok, _ := store.Last("GAZP", Interval_D1); ok {
+		t.Fatal("свечи другого символа")
+	}
+	if _, ok, _ := store.Last("SBER", Interval_H1); ok {
+		t.Fatal("свечи другого интервала")
+	}
+}
+
+// строки длиннее блока чтения scanBack
+func TestScanBackLongLines(t *testing.T) {
+	long := func(c byte, n int) string { return strings.Repeat(string(c), n) }
+	lines := []string{long('a', 10), long('b', 150*1024), long('c', 5), long('d', 70*1024), long('e', 3)}
+	path := filepath.Join(t.TempDir(), "lines.csv")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	offsets := make([]int64, len(lines)+1) // offsets[i] = начало строки i
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + int64(len(line)) + 1
+	}
+	for accept := 0; accept <= len(lines); accept++ {
+		var seen []string
+		offset, err := scanBack(f, func(line string) bool {
+			seen = append(seen, line)
+			return len(seen) <= accept
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := offsets[len(lines)-accept]; offset != want {
+			t.Errorf("принято %d строк: смещение %d, ожидали %d", accept, offset, want)
+		}
+		// строки приходят с конца целиком
+		for i, line := range seen {
+			if want := lines[len(lines)-1-i]; line != want {
+				t.Fatalf("принято %d строк: строка %d длиной %d, ожидали %d", accept, i, len(line), len(want))
+			}
+		}
+	}
+
+	// последняя строка без перевода строки
+	if err = os.WriteFile(path, []byte("x\n"+long('y', 100*1024)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var last string
+	offset, err := scanBack(f, func(line string) bool {
+		last = line
+		return true
+	})
+	if err != nil || offset != 0 || last != "x" {
+		t.Fatalf("без перевода строки: %d %q %v", offset, last, err)
+	}
+}
+
+// fakeCandleDays дневные свечи SBER с учетом from: сервер отдает и свечу, пересекающуюся с from (день раньше)
+type fakeCandleDays struct {
+	mu    sync.Mutex
+	close map[int]float64 // день августа -> close
+}
+
+func (fc *fakeCandleDays) handler(q url.Values) string {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	var first time.Time
+	if from := q.Get("from"); from != "" {
+		first, _ = time.Parse(dateLayout, from[:len(dateLayout)])
+		first = first.AddDate(0, 0, -1)
+	}
+	var days []int
+	for day := 1; day <= 31; day++ {
+		if _, ok := fc.close[day]; ok && !time.Date(2024, 8, day, 0, 0, 0, 0, time.UTC).Before(first) {
+			days = append(days, day)
+		}
+	}
+	start, _ := strconv.Atoi(q.Get("start"))
+	rows := make([][]interface{}, 0)
+	for i := start; i < start+2 && i < len(days); i++ {
+		c := dayCandle(days[i], fc.close[days[i]])
+		rows = append(rows, []interface{}{c.Open, c.Close, c.High, c.Low, c.Value, c.Volume, c.Begin, c.End})
+	}
+	return issJSON(map[string]Table{"candles": table(candleColumns, rows...)})
+}
+
+func TestSyncCandles(t *testing.T) {
+	const path = "engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json"
+	f := newFakeISS()
+	fakeSber(f)
+	fc := &fakeCandleDays{close: map[int]float64{1: 301, 2: 302, 5: 305, 6: 306}}
+	f.handle(path, fc.handler)
+	ticker, err := newFakeClient(t, f).GetTicker("SBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileCandleStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// пустое хранилище = с начала истории (candleborders)
+	if err = SyncCandles(context.Background(), store, []*Ticker{ticker}, Interval_D1); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedBegins(t, store, "SBER"); got != "01,02,05,06" {
+		t.Fatalf("первая загрузка: %s", got)
+	}
+	if q, _ := f.last(path); !strings.HasPrefix(q.Get("from"), "2011-12-15") {
+		t.Fatalf("первая загрузка from %q", q.Get("from"))
+	}
+
+	// последняя свеча изменилась, появились новые
+	fc.mu.Lock()
+	fc.close[6], fc.close[7], fc.close[8] = 316, 307, 308
+	fc.mu.Unlock()
+	if err = SyncCandles(context.Background(), store, []*Ticker{ticker}, Interval_D1); err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := f.last(path); !strings.HasPrefix(q.Get("from"), "2024-08-06") {
+		t.Fatalf("продолжение from %q", q.Get("from"))
+	}
+	if got := storedBegins(t, store, "SBER"); got != "01,02,05,06,07,08" {
+		t.Fatalf("продолжение: %s", got)
+	}
+	last, _, _ := store.Last("SBER", Interval_D1)
+	from := time.Date(2024, 8, 6, 0, 0, 0, 0, TzMsk)
+	if got, _ := store.Range("SBER", Interval_D1, from, from); len(got) != 1 || got[0].Close != 316 || last.Close != 308 {
+		t.Fatalf("перезапись последней свечи: %+v, last %+v", got, last)
+	}
+
+	// отмена ctx = ошибка по символу
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = SyncCandles(ctx, store, []*Ticker{ticker}, Interval_D1); err == nil || !strings.Contains(err.Error(), "SBER") {
+		t.Fatalf("после отмены: %v", err)
+	}
+}
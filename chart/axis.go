@@ -0,0 +1,49 @@
+package chart
+
+import (
+	"math"
+	"strconv"
+)
+
+// niceTicks деления оси: шаг 1, 2 или 5 * 10^k, примерно n делений
+func niceTicks(lo, hi float64, n int) ([]float64, float64) {
+	if !(hi > lo) || n < 1 {
+		return nil, 0
+	}
+	raw := (hi - lo) / float64(n)
+	pow := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := 10 * pow
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*pow {
+			step = m * pow
+			break
+		}
+	}
+	var ticks []float64
+	for v := math.Ceil(lo/step) * step; v <= hi; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks, step
+}
+
+// formatValue подпись деления: знаков после запятой столько, сколько нужно для шага
+func formatValue(v, step float64) string {
+	decimals := 0
+	if step > 0 && step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// formatVolume объем с суффиксом K, M, B
+func formatVolume(v float64) string {
+	switch a := math.Abs(v); {
+	case a >= 1e9:
+		return strconv.FormatFloat(v/1e9, 'f', -1, 64) + "B"
+	case a >= 1e6:
+		return strconv.FormatFloat(v/1e6, 'f', -1, 64) + "M"
+	case a >= 1e3:
+		return strconv.FormatFloat(v/1e3, 'f', -1, 64) + "K"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,101 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// anchor выравнивание подписи относительно x
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// point точка в пикселях
+type point struct {
+	x, y float64
+}
+
+// canvas примитивы рисования: одна раскладка графика рисуется и в SVG, и в PNG
+type canvas interface {
+	rect(x, y, w, h float64, fill color.RGBA)
+	line(x1, y1, x2, y2 float64, stroke color.RGBA, width float64, dashed bool)
+	polyline(points []point, stroke color.RGBA, width float64)
+	// text подпись: y = базовая линия
+	text(x, y float64, s string, fill color.RGBA, a anchor)
+}
+
+// svgCanvas элементы svg
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func newSVGCanvas(width, height int, background color.RGBA) *svgCanvas {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		width, height, width, height)
+	c.rect(0, 0, float64(width), float64(height), background)
+	return c
+}
+
+// bytes законченный документ
+func (c *svgCanvas) bytes() []byte {
+	c.buf.WriteString("</svg>\n")
+	return c.buf.Bytes()
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill color.RGBA) {
+	fmt.Fprintf(&c.buf, `<rect x="%s" y="%s" width="%s" height="%s" %s/>`+"\n",
+		num(x), num(y), num(max(w, 0)), num(max(h, 0)), paint("fill", fill))
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2 float64, stroke color.RGBA, width float64, dashed bool) {
+	dash := ""
+	if dashed {
+		dash = ` stroke-dasharray="4 3"`
+	}
+	fmt.Fprintf(&c.buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" %s stroke-width="%s"%s/>`+"\n",
+		num(x1), num(y1), num(x2), num(y2), paint("stroke", stroke), num(width), dash)
+}
+
+func (c *svgCanvas) polyline(points []point, stroke color.RGBA, width float64) {
+	if len(points) < 2 {
+		return
+	}
+	c.buf.WriteString(`<polyline fill="none" points="`)
+	for i, p := range points {
+		if i > 0 {
+			c.buf.WriteByte(' ')
+		}
+		c.buf.WriteString(num(p.x) + "," + num(p.y))
+	}
+	fmt.Fprintf(&c.buf, `" %s stroke-width="%s"/>`+"\n", paint("stroke", stroke), num(width))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, fill color.RGBA, a anchor) {
+	anchors := [...]string{"start", "middle", "end"}
+	fmt.Fprintf(&c.buf, `<text x="%s" y="%s" text-anchor="%s" %s>`, num(x), num(y), anchors[a], paint("fill", fill))
+	_ = xml.EscapeText(&c.buf, []byte(s))
+	c.buf.WriteString("</text>\n")
+}
+
+// paint атрибут цвета (с прозрачностью)
+func paint(attr string, c color.RGBA) string {
+	s := fmt.Sprintf(`%s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A != 0xff {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, strconv.FormatFloat(float64(c.A)/255, 'f', 2, 64))
+	}
+	return s
+}
+
+// num координата с точностью до 0.1 пикселя
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
@@ -0,0 +1,349 @@
+/*
+Package chart графики для отчетов: свечной график Candles и график глубины стакана OrderBook
+
+форматы: SVG и PNG (растеризация без внешних зависимостей, подписи в PNG встроенным шрифтом 5x7:
+цифры, латиница и кириллица прописными буквами, прочие символы пропускаются = для них SVG)
+
+свечи идут подряд без пропусков на ночь и выходные, время по Москве,
+границы дней = сплошная линия, границы торговых сессий внутри дня = пунктир
+
+	c := chart.New(candles, chart.WithSessions(iss.MarketSessions(iss.MarketShares)...))
+	c.AddLine("SMA 20", indicators.CalcSMA(indicators.Closes(bars), 20), chart.Blue)
+	err := c.PNG(file)
+*/
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+// ErrNoData нет данных для графика
+var ErrNoData = errors.New("нет данных для графика")
+
+// ErrSize размер графика меньше минимального
+var ErrSize = errors.New("размер графика меньше минимального")
+
+// Цвета графика.
+var (
+	Background = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	Grid       = color.RGBA{R: 0xe6, G: 0xe6, B: 0xe6, A: 0xff}
+	Axis       = color.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xff}
+	Up         = color.RGBA{R: 0x26, G: 0xa6, B: 0x9a, A: 0xff}
+	Down       = color.RGBA{R: 0xef, G: 0x53, B: 0x50, A: 0xff}
+	Blue       = color.RGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}
+	Orange     = color.RGBA{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff}
+	Purple     = color.RGBA{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff}
+	Gray       = color.RGBA{R: 0x9e, G: 0x9e, B: 0x9e, A: 0xff}
+)
+
+// отступы области графика: справа шкала цены, снизу шкала времени
+const (
+	marginLeft   = 10
+	marginRight  = 70
+	marginTop    = 30
+	marginBottom = 25
+	paneGap      = 8
+
+	// минимальная область графика внутри отступов
+	minPlotWidth  = 100
+	minPlotHeight = 100
+)
+
+// options параметры графика
+type options struct {
+	width, height int
+	title         string
+	volume        bool
+	sessions      []iss.Session
+}
+
+// Option параметр графика
+type Option func(*options)
+
+// WithSize размер в пикселях (по умолчанию 1000 x 600)
+// область графика внутри отступов не меньше 100 x 100, иначе SVG и PNG вернут ErrSize
+func WithSize(width, height int) Option {
+	return func(o *options) {
+		o.width, o.height = width, height
+	}
+}
+
+// WithTitle заголовок (по умолчанию код инструмента и интервал)
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithVolume показывать гистограмму объема (по умолчанию да)
+func WithVolume(volume bool) Option {
+	return func(o *options) {
+		o.volume = volume
+	}
+}
+
+// WithSessions торговые сессии для отметки границ (по умолчанию iss.DefaultSessions)
+func WithSessions(sessions ...iss.Session) Option {
+	return func(o *options) {
+		o.sessions = sessions
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{width: 1000, height: 600, volume: true, sessions: iss.DefaultSessions}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// validate проверить размер: нулевой или малый размер дает перевернутую область графика
+func (o options) validate() error {
+	if o.width < marginLeft+marginRight+minPlotWidth || o.height < marginTop+marginBottom+minPlotHeight {
+		return fmt.Errorf("%w: %d x %d", ErrSize, o.width, o.height)
+	}
+	return nil
+}
+
+// line линия индикатора поверх свечей
+type line struct {
+	name   string
+	values []float64
+	color  color.RGBA
+}
+
+// CandleChart свечной график
+type CandleChart struct {
+	candles iss.Candles
+	opts    options
+	lines   []line
+}
+
+// New свечной график
+func New(candles iss.Candles, opts ...Option) *CandleChart {
+	c := &CandleChart{candles: candles, opts: newOptions(opts)}
+	if c.opts.title == "" {
+		c.opts.title = candles.Symbol + " " + candles.Interval
+	}
+	return c
+}
+
+// AddLine линия индикатора поверх свечей: values[i] относится к свече i, NaN = нет значения
+// (подходит результат indicators.CalcSMA, CalcEMA, CalcVWAP ...)
+func (c *CandleChart) AddLine(name string, values []float64, col color.RGBA) *CandleChart {
+	c.lines = append(c.lines, line{name: name, values: values, color: col})
+	return c
+}
+
+// SVG записать график в формате SVG
+func (c *CandleChart) SVG(w io.Writer) error {
+	if err := c.opts.validate(); err != nil {
+		return err
+	}
+	cv := newSVGCanvas(c.opts.width, c.opts.height, Background)
+	if err := c.draw(cv); err != nil {
+		return err
+	}
+	_, err := w.Write(cv.bytes())
+	return err
+}
+
+// PNG записать график в формате PNG
+func (c *CandleChart) PNG(w io.Writer) error {
+	if err := c.opts.validate(); err != nil {
+		return err
+	}
+	cv := newRasterCanvas(c.opts.width, c.opts.height, Background)
+	if err := c.draw(cv); err != nil {
+		return err
+	}
+	return png.Encode(w, cv.img)
+}
+
+// draw раскладка графика
+func (c *CandleChart) draw(cv canvas) error {
+	data := c.candles.Data
+	if len(data) == 0 {
+		return ErrNoData
+	}
+	o := c.opts
+	left, right := float64(marginLeft), float64(o.width-marginRight)
+	top, bottom := float64(marginTop), float64(o.height-marginBottom)
+	priceBottom, volumeTop := bottom, bottom
+	if o.volume {
+		volumeTop = top + (bottom-top)*0.78
+		priceBottom = volumeTop - paneGap
+	}
+
+	// диапазон цен: свечи и линии индикаторов
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, k := range data {
+		lo, hi = min(lo, k.Low), max(hi, k.High)
+	}
+	for _, l := range c.lines {
+		for _, v := range l.values {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				lo, hi = min(lo, v), max(hi, v)
+			}
+		}
+	}
+	if hi == lo {
+		hi, lo = hi+1, lo-1
+	}
+	pad := (hi - lo) * 0.05
+	lo, hi = lo-pad, hi+pad
+	priceY := func(p float64) float64 {
+		return priceBottom - (p-lo)/(hi-lo)*(priceBottom-top)
+	}
+
+	slot := (right - left) / float64(len(data))
+	centerX := func(i int) float64 {
+		return left + slot*(float64(i)+0.5)
+	}
+
+	// сетка и шкала цены
+	ticks, step := niceTicks(lo, hi, 6)
+	for _, p := range ticks {
+		y := priceY(p)
+		cv.line(left, y, right, y, Grid, 1, false)
+		cv.text(right+6, y+4, formatValue(p, step), Axis, anchorStart)
+	}
+
+	c.drawTimeAxis(cv, left, right, top, bottom, slot, centerX)
+
+	// свечи
+	body := max(slot*0.7, 1)
+	for i, k := range data {
+		col := Up
+		if k.Close < k.Open {
+			col = Down
+		}
+		x := centerX(i)
+		cv.line(x, priceY(k.High), x, priceY(k.Low), col, 1, false)
+		y1, y2 := priceY(max(k.Open, k.Close)), priceY(min(k.Open, k.Close))
+		cv.rect(x-body/2, y1, body, max(y2-y1, 1), col)
+	}
+
+	// линии индикаторов (NaN разрывает линию)
+	for _, l := range c.lines {
+		var points []point
+		for i := 0; i < len(data) && i < len(l.values); i++ {
+			v := l.values[i]
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				cv.polyline(points, l.color, 1.5)
+				points = points[:0]
+				continue
+			}
+			points = append(points, point{x: centerX(i), y: priceY(v)})
+		}
+		cv.polyline(points, l.color, 1.5)
+	}
+
+	// объем
+	if o.volume {
+		maxVolume := 0.0
+		for _, k := range data {
+			maxVolume = max(maxVolume, k.Volume)
+		}
+		cv.line(left, volumeTop, right, volumeTop, Grid, 1, false)
+		if maxVolume > 0 {
+			cv.text(right+6, volumeTop+10, formatVolume(maxVolume), Axis, anchorStart)
+			for i, k := range data {
+				col := Up
+				if k.Close < k.Open {
+					col = Down
+				}
+				col.A = 0x80
+				h := k.Volume / maxVolume * (bottom - volumeTop)
+				cv.rect(centerX(i)-body/2, bottom-h, body, h, col)
+			}
+		}
+	}
+
+	// рамка, заголовок, легенда
+	cv.line(left, bottom, right, bottom, Axis, 1, false)
+	cv.line(right, top, right, bottom, Axis, 1, false)
+	cv.text(left, 18, o.title, Axis, anchorStart)
+	x := left + float64(len([]rune(o.title))*glyphAdvance) + 20
+	for _, l := range c.lines {
+		cv.rect(x, 11, 12, 3, l.color)
+		cv.text(x+16, 18, l.name, l.color, anchorStart)
+		x += float64(len([]rune(l.name))*glyphAdvance) + 36
+	}
+	return nil
+}
+
+// drawTimeAxis подписи времени (МСК) и границы дней и сессий
+func (c *CandleChart) drawTimeAxis(cv canvas, left, right, top, bottom, slot float64, centerX func(int) float64) {
+	data := c.candles.Data
+	times := make([]time.Time, len(data))
+	intraday := false
+	for i, k := range data {
+		times[i] = k.Time()
+		if i > 0 && sameDay(times[i-1], times[i]) {
+			intraday = true
+		}
+	}
+
+	// границы: между свечами i-1 и i
+	// свечи вне сессий (аукционы, клиринг) границу не образуют
+	if intraday {
+		session := sessionIndex(times[0], c.opts.sessions)
+		for i := 1; i < len(times); i++ {
+			x := centerX(i) - slot/2
+			cur := sessionIndex(times[i], c.opts.sessions)
+			switch {
+			case !sameDay(times[i-1], times[i]):
+				cv.line(x, top, x, bottom, Gray, 1, false)
+				session = cur
+			case cur >= 0 && session >= 0 && cur != session:
+				cv.line(x, top, x, bottom, Gray, 1, true)
+			}
+			if cur >= 0 {
+				session = cur
+			}
+		}
+	}
+
+	// подписи примерно каждые 110 пикселей
+	layout := "02.01.06"
+	if intraday {
+		layout = "02.01 15:04"
+	}
+	every := max(int(110/slot), 1)
+	for i := 0; i < len(times); i += every {
+		x := centerX(i)
+		if x < left+35 {
+			continue // подпись не поместится
+		}
+		if x > right-35 {
+			break
+		}
+		cv.line(x, bottom, x, bottom+4, Axis, 1, false)
+		cv.text(x, bottom+16, times[i].In(iss.TzMsk).Format(layout), Axis, anchorMiddle)
+	}
+}
+
+// sameDay один день по Москве
+func sameDay(a, b time.Time) bool {
+	return iss.Interval_D1.Truncate(a).Equal(iss.Interval_D1.Truncate(b))
+}
+
+// sessionIndex номер сессии, в которую попадает время (-1 = вне сессий)
+func sessionIndex(t time.Time, sessions []iss.Session) int {
+	offset := t.Sub(iss.Interval_D1.Truncate(t))
+	for i, s := range sessions {
+		if s.Contains(offset) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,271 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+// testCandles свечи M10 с 10:00, цены по closes (open = предыдущий close)
+func testCandles(closes ...float64) iss.Candles {
+	start := time.Date(2024, 8, 6, 10, 0, 0, 0, iss.TzMsk)
+	k := iss.Candles{Symbol: "SBER", Interval: iss.Interval_M10.String()}
+	for i, c := range closes {
+		open := c
+		if i > 0 {
+			open = closes[i-1]
+		}
+		begin := start.Add(time.Duration(i) * 10 * time.Minute)
+		k.Data = append(k.Data, iss.Candle{
+			Open: open, Close: c, High: max(open, c) + 0.5, Low: min(open, c) - 0.5, Volume: 1000 * float64(i+1),
+			Begin: begin.Format("2006-01-02 15:04:05"), End: begin.Add(10*time.Minute - time.Second).Format("2006-01-02 15:04:05"),
+		})
+	}
+	return k
+}
+
+// svgElement элемент svg с атрибутами
+type svgElement struct {
+	name string
+	attr map[string]string
+}
+
+// parseSVG разобрать документ: корень svg с размером, координаты конечные и внутри размера
+func parseSVG(t *testing.T, data []byte, width, height int) []svgElement {
+	t.Helper()
+	var elements []svgElement
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("svg: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		e := svgElement{name: start.Name.Local, attr: make(map[string]string)}
+		for _, a := range start.Attr {
+			e.attr[a.Name.Local] = a.Value
+		}
+		elements = append(elements, e)
+	}
+	if len(elements) == 0 || elements[0].name != "svg" ||
+		elements[0].attr["width"] != strconv.Itoa(width) || elements[0].attr["height"] != strconv.Itoa(height) {
+		t.Fatalf("корень svg %+v", elements[:min(len(elements), 1)])
+	}
+
+	check := func(e svgElement, v string, limit int) {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || f < 0 || f > float64(limit) {
+			t.Errorf("%s: координата %q вне 0..%d: %v", e.name, v, limit, e.attr)
+		}
+	}
+	for _, e := range elements[1:] {
+		for name, v := range e.attr {
+			switch name {
+			case "x", "x1", "x2", "width":
+				check(e, v, width)
+			case "y", "y1", "y2", "height":
+				check(e, v, height)
+			case "points":
+				for _, p := range strings.Fields(v) {
+					x, y, _ := strings.Cut(p, ",")
+					check(e, x, width)
+					check(e, y, height)
+				}
+			}
+		}
+	}
+	return elements
+}
+
+// polylines линии цвета col
+func polylines(elements []svgElement, col string) []svgElement {
+	var result []svgElement
+	for _, e := range elements {
+		if e.name == "polyline" && e.attr["stroke"] == col {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func hex(c interface{ RGBA() (r, g, b, a uint32) }) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func TestCandleChart(t *testing.T) {
+	candles := testCandles(300, 301, 303, 302, 304, 306, 305, 303, 302, 304, 307, 308)
+	sma := make([]float64, len(candles.Data))
+	for i := range sma {
+		sma[i] = 300 + float64(i)
+	}
+	c := New(candles, WithSize(800, 400), WithTitle("Сбербанк")).AddLine("SMA", sma, Blue)
+
+	var buf bytes.Buffer
+	if err := c.SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	elements := parseSVG(t, buf.Bytes(), 800, 400)
+	// фон + тело каждой свечи + столбец объема
+	rects := 0
+	for _, e := range elements {
+		if e.name == "rect" {
+			rects++
+		}
+	}
+	if rects < 1+2*len(candles.Data) {
+		t.Errorf("прямоугольников %d", rects)
+	}
+	if n := len(polylines(elements, hex(Blue))); n != 1 {
+		t.Errorf("линий индикатора %d", n)
+	}
+	if !strings.Contains(buf.String(), ">Сбербанк</text>") {
+		t.Error("нет заголовка")
+	}
+
+	buf.Reset()
+	if err := c.PNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 800 || b.Dy() != 400 {
+		t.Fatalf("размер PNG %v", b)
+	}
+	// фон в углу, свечи в области графика
+	if hex(img.At(0, 0)) != hex(Background) {
+		t.Errorf("фон %v", img.At(0, 0))
+	}
+	colored := 0
+	for y := marginTop; y < 400-marginBottom; y++ {
+		for x := marginLeft; x < 800-marginRight; x++ {
+			if h := hex(img.At(x, y)); h == hex(Up) || h == hex(Down) {
+				colored++
+			}
+		}
+	}
+	if colored == 0 {
+		t.Error("свечи не нарисованы")
+	}
+}
+
+func TestCandleChartNoData(t *testing.T) {
+	c := New(iss.Candles{Symbol: "SBER"})
+	var buf bytes.Buffer
+	if err := c.SVG(&buf); !errors.Is(err, ErrNoData) {
+		t.Fatalf("SVG: %v", err)
+	}
+	if err := c.PNG(&buf); !errors.Is(err, ErrNoData) {
+		t.Fatalf("PNG: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("записано %d байт", buf.Len())
+	}
+}
+
+// NaN разрывает линию индикатора, линия из одной точки не рисуется
+func TestCandleChartNaN(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		values []float64
+		lines  int
+	}{
+		{"без NaN", []float64{300, 301, 302, 303, 304, 305}, 1},
+		{"NaN в начале", []float64{nan, nan, 302, 303, 304, 305}, 1},
+		{"NaN в середине", []float64{300, 301, nan, 303, 304, 305}, 2},
+		{"одиночная точка", []float64{300, nan, 302, nan, 304, 305}, 1},
+		{"только NaN", []float64{nan, nan, nan, nan, nan, nan}, 0},
+		{"Inf", []float64{300, 301, math.Inf(1), 303, 304, 305}, 2},
+		{"короче свечей", []float64{300, 301}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(testCandles(300, 302, 301, 304, 303, 305), WithSize(600, 300)).AddLine("SMA", tt.values, Orange)
+			var buf bytes.Buffer
+			if err := c.SVG(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if n := len(polylines(parseSVG(t, buf.Bytes(), 600, 300), hex(Orange))); n != tt.lines {
+				t.Errorf("линий %d, ожидали %d", n, tt.lines)
+			}
+		})
+	}
+}
+
+// все цены равны (hi == lo): график без NaN в координатах
+func TestCandleChartFlat(t *testing.T) {
+	candles := testCandles(300, 300, 300)
+	for i := range candles.Data {
+		candles.Data[i].High, candles.Data[i].Low = 300, 300
+	}
+	for _, volume := range []bool{true, false} {
+		c := New(candles, WithSize(600, 300), WithVolume(volume)).AddLine("SMA", []float64{300, 300, 300}, Blue)
+		var buf bytes.Buffer
+		if err := c.SVG(&buf); err != nil {
+			t.Fatal(err)
+		}
+		parseSVG(t, buf.Bytes(), 600, 300)
+		buf.Reset()
+		if err := c.PNG(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := png.Decode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWithSize(t *testing.T) {
+	candles := testCandles(300, 301)
+	book := iss.OrderBook{Bids: iss.PriceVolumeSlice{{Price: 300, Volume: 10}}}
+	tests := []struct {
+		width, height int
+		ok            bool
+	}{
+		{0, 0, false},
+		{-800, 600, false},
+		{800, -600, false},
+		{marginLeft + marginRight + minPlotWidth - 1, 600, false},
+		{800, marginTop + marginBottom + minPlotHeight - 1, false},
+		{marginLeft + marginRight + minPlotWidth, marginTop + marginBottom + minPlotHeight, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%dx%d", tt.width, tt.height), func(t *testing.T) {
+			renders := map[string]func(io.Writer) error{
+				"SVG":       New(candles, WithSize(tt.width, tt.height)).SVG,
+				"PNG":       New(candles, WithSize(tt.width, tt.height)).PNG,
+				"Depth SVG": NewDepth(book, WithSize(tt.width, tt.height)).SVG,
+				"Depth PNG": NewDepth(book, WithSize(tt.width, tt.height)).PNG,
+			}
+			for name, render := range renders {
+				var buf bytes.Buffer
+				err := render(&buf)
+				if tt.ok && err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+				if !tt.ok && (!errors.Is(err, ErrSize) || buf.Len() != 0) {
+					t.Errorf("%s: %v, записано %d байт", name, err, buf.Len())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package chart
+
+import (
+	"cmp"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+// DepthChart график глубины стакана: накопленный объем бидов и асков по ценам
+type DepthChart struct {
+	book iss.OrderBook
+	opts options
+}
+
+// NewDepth график глубины стакана (WithVolume и WithSessions не используются)
+func NewDepth(book iss.OrderBook, opts ...Option) *DepthChart {
+	d := &DepthChart{book: book, opts: newOptions(opts)}
+	if d.opts.title == "" {
+		d.opts.title = "Depth " + book.UpdateTime
+	}
+	return d
+}
+
+// SVG записать график в формате SVG
+func (d *DepthChart) SVG(w io.Writer) error {
+	if err := d.opts.validate(); err != nil {
+		return err
+	}
+	cv := newSVGCanvas(d.opts.width, d.opts.height, Background)
+	if err := d.draw(cv); err != nil {
+		return err
+	}
+	_, err := w.Write(cv.bytes())
+	return err
+}
+
+// PNG записать график в формате PNG
+func (d *DepthChart) PNG(w io.Writer) error {
+	if err := d.opts.validate(); err != nil {
+		return err
+	}
+	cv := newRasterCanvas(d.opts.width, d.opts.height, Background)
+	if err := d.draw(cv); err != nil {
+		return err
+	}
+	return png.Encode(w, cv.img)
+}
+
+// draw биды слева от середины спреда, аски справа
+// на уровне цены высота = объем по этой цене и всем лучшим ценам
+func (d *DepthChart) draw(cv canvas) error {
+	bids := slices.Clone(d.book.Bids)
+	asks := slices.Clone(d.book.Asks)
+	if len(bids) == 0 && len(asks) == 0 {
+		return ErrNoData
+	}
+	// лучшая цена первой
+	slices.SortFunc(bids, func(a, b iss.PriceVolume) int { return cmp.Compare(b.Price, a.Price) })
+	slices.SortFunc(asks, func(a, b iss.PriceVolume) int { return cmp.Compare(a.Price, b.Price) })
+
+	o := d.opts
+	left, right := float64(marginLeft), float64(o.width-marginRight)
+	top, bottom := float64(marginTop), float64(o.height-marginBottom)
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	var total float64
+	for _, side := range []iss.PriceVolumeSlice{bids, asks} {
+		for _, pv := range side {
+			lo, hi = min(lo, pv.Price), max(hi, pv.Price)
+		}
+		total = max(total, float64(side.SumDepth()))
+	}
+	levels := max(len(bids), len(asks), 1)
+	// запас по краям = средний шаг цены, чтобы крайний уровень имел ширину
+	pad := (hi - lo) / float64(levels)
+	if pad == 0 {
+		pad = math.Max(math.Abs(hi)*0.001, 0.01)
+	}
+	lo, hi = lo-pad, hi+pad
+	if total == 0 {
+		total = 1
+	}
+	priceX := func(p float64) float64 {
+		return left + (p-lo)/(hi-lo)*(right-left)
+	}
+	volumeY := func(v float64) float64 {
+		return bottom - v/(total*1.05)*(bottom-top)
+	}
+
+	// сетка: объем справа, цены снизу
+	ticks, _ := niceTicks(0, total*1.05, 5)
+	for _, v := range ticks {
+		y := volumeY(v)
+		cv.line(left, y, right, y, Grid, 1, false)
+		cv.text(right+6, y+4, formatVolume(v), Axis, anchorStart)
+	}
+	ticks, step := niceTicks(lo, hi, 6)
+	for _, p := range ticks {
+		x := priceX(p)
+		cv.line(x, bottom, x, bottom+4, Axis, 1, false)
+		cv.text(x, bottom+16, formatValue(p, step), Axis, anchorMiddle)
+	}
+
+	// ступени: от цены уровня до цены следующего (к краю графика)
+	drawSide := func(side iss.PriceVolumeSlice, edge float64, col color.RGBA) {
+		fill := col
+		fill.A = 0x60
+		var cum float64
+		var outline []point
+		for i, pv := range side {
+			cum += float64(pv.Volume)
+			next := edge
+			if i+1 < len(side) {
+				next = side[i+1].Price
+			}
+			x1, x2 := priceX(pv.Price), priceX(next)
+			y := volumeY(cum)
+			cv.rect(min(x1, x2), y, math.Abs(x2-x1), bottom-y, fill)
+			if i == 0 {
+				outline = append(outline, point{x: x1, y: bottom})
+			}
+			outline = append(outline, point{x: x1, y: y}, point{x: x2, y: y})
+		}
+		cv.polyline(outline, col, 1.5)
+	}
+	drawSide(bids, lo, Up)
+	drawSide(asks, hi, Down)
+
+	// середина спреда
+	if len(bids) > 0 && len(asks) > 0 {
+		mid := (bids[0].Price + asks[0].Price) / 2
+		x := priceX(mid)
+		cv.line(x, top, x, bottom, Gray, 1, true)
+		cv.text(x, top-4, formatValue(mid, step/10), Axis, anchorMiddle)
+	}
+
+	cv.line(left, bottom, right, bottom, Axis, 1, false)
+	cv.line(right, top, right, bottom, Axis, 1, false)
+	cv.text(left, 18, o.title, Axis, anchorStart)
+	return nil
+}
@@ -0,0 +1,79 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+func TestDepthChart(t *testing.T) {
+	bids := iss.PriceVolumeSlice{{Price: 306.0, Volume: 50}, {Price: 306.1, Volume: 10}, {Price: 305.9, Volume: 200}}
+	asks := iss.PriceVolumeSlice{{Price: 306.3, Volume: 30}, {Price: 306.2, Volume: 5}}
+	tests := []struct {
+		name      string
+		book      iss.OrderBook
+		bids      bool
+		asks      bool
+		midSpread bool
+	}{
+		{"обе стороны", iss.OrderBook{Bids: bids, Asks: asks}, true, true, true},
+		{"только биды", iss.OrderBook{Bids: bids}, true, false, false},
+		{"только аски", iss.OrderBook{Asks: asks}, false, true, false},
+		{"один уровень", iss.OrderBook{Asks: asks[:1]}, false, true, false},
+		{"нулевой объем", iss.OrderBook{Bids: iss.PriceVolumeSlice{{Price: 306, Volume: 0}}}, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDepth(tt.book, WithSize(600, 300))
+			var buf bytes.Buffer
+			if err := d.SVG(&buf); err != nil {
+				t.Fatal(err)
+			}
+			elements := parseSVG(t, buf.Bytes(), 600, 300)
+			if got := len(polylines(elements, hex(Up))) == 1; got != tt.bids {
+				t.Errorf("ступени бидов: %v", got)
+			}
+			if got := len(polylines(elements, hex(Down))) == 1; got != tt.asks {
+				t.Errorf("ступени асков: %v", got)
+			}
+			// середина спреда = вертикальный пунктир
+			mid := false
+			for _, e := range elements {
+				if e.name == "line" && e.attr["stroke-dasharray"] != "" {
+					mid = true
+				}
+			}
+			if mid != tt.midSpread {
+				t.Errorf("середина спреда: %v", mid)
+			}
+
+			buf.Reset()
+			if err := d.PNG(&buf); err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != 600 || b.Dy() != 300 {
+				t.Fatalf("размер PNG %v", b)
+			}
+		})
+	}
+
+	// лучшие цены сортируются, исходный стакан не меняется
+	if bids[0].Price != 306.0 || asks[0].Price != 306.3 {
+		t.Fatal("draw изменил стакан")
+	}
+
+	var buf bytes.Buffer
+	if err := NewDepth(iss.OrderBook{}).SVG(&buf); !errors.Is(err, ErrNoData) {
+		t.Fatalf("пустой стакан: %v", err)
+	}
+	if err := NewDepth(iss.OrderBook{}).PNG(&buf); !errors.Is(err, ErrNoData) || buf.Len() != 0 {
+		t.Fatalf("пустой стакан: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// rasterCanvas рисование в image.RGBA без внешних зависимостей
+// подписи = встроенный шрифт 5x7 (цифры, латиница, кириллица, знаки), остальные символы пропускаются
+type rasterCanvas struct {
+	img *image.RGBA
+}
+
+func newRasterCanvas(width, height int, background color.RGBA) *rasterCanvas {
+	c := &rasterCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+	c.rect(0, 0, float64(width), float64(height), background)
+	return c
+}
+
+// blend наложение пикселя с учетом прозрачности
+func (c *rasterCanvas) blend(x, y int, col color.RGBA) {
+	if !(image.Point{X: x, Y: y}.In(c.img.Rect)) {
+		return
+	}
+	if col.A == 0xff {
+		c.img.SetRGBA(x, y, col)
+		return
+	}
+	dst := c.img.RGBAAt(x, y)
+	a := uint32(col.A)
+	mix := func(s, d uint8) uint8 {
+		return uint8((uint32(s)*a + uint32(d)*(255-a)) / 255)
+	}
+	c.img.SetRGBA(x, y, color.RGBA{R: mix(col.R, dst.R), G: mix(col.G, dst.G), B: mix(col.B, dst.B), A: 0xff})
+}
+
+func (c *rasterCanvas) rect(x, y, w, h float64, fill color.RGBA) {
+	x0, y0 := int(math.Round(x)), int(math.Round(y))
+	x1, y1 := int(math.Round(x+w)), int(math.Round(y+h))
+	if x1 == x0 && w > 0 {
+		x1++ // хотя бы один пиксель
+	}
+	if y1 == y0 && h > 0 {
+		y1++
+	}
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			c.blend(px, py, fill)
+		}
+	}
+}
+
+// line отрезок шагами по полпикселя, толщина = квадрат width x width
+func (c *rasterCanvas) line(x1, y1, x2, y2 float64, stroke color.RGBA, width float64, dashed bool) {
+	w := max(int(math.Round(width)), 1)
+	length := math.Hypot(x2-x1, y2-y1)
+	steps := max(int(length*2), 1)
+	last := image.Point{X: math.MinInt, Y: math.MinInt}
+	for i := 0; i <= steps; i++ {
+		d := length * float64(i) / float64(steps)
+		if dashed && math.Mod(d, 7) >= 4 {
+			continue // штрих 4 пикселя, пропуск 3
+		}
+		px := int(math.Round(x1 + (x2-x1)*float64(i)/float64(steps)))
+		py := int(math.Round(y1 + (y2-y1)*float64(i)/float64(steps)))
+		if last.X == px && last.Y == py {
+			continue
+		}
+		last = image.Point{X: px, Y: py}
+		for dy := 0; dy < w; dy++ {
+			for dx := 0; dx < w; dx++ {
+				c.blend(px+dx-w/2, py+dy-w/2, stroke)
+			}
+		}
+	}
+}
+
+func (c *rasterCanvas) polyline(points []point, stroke color.RGBA, width float64) {
+	for i := 1; i < len(points); i++ {
+		c.line(points[i-1].x, points[i-1].y, points[i].x, points[i].y, stroke, width, false)
+	}
+}
+
+func (c *rasterCanvas) text(x, y float64, s string, fill color.RGBA, a anchor) {
+	s = strings.ToUpper(s)
+	width := float64(len([]rune(s))*glyphAdvance - 1)
+	switch a {
+	case anchorMiddle:
+		x -= width / 2
+	case anchorEnd:
+		x -= width
+	}
+	px, top := int(math.Round(x)), int(math.Round(y))-glyphHeight
+	for _, r := range s {
+		if glyph, ok := font5x7[r]; ok {
+			for row, bits := range glyph {
+				for col := 0; col < 5; col++ {
+					if bits&(0x10>>col) != 0 {
+						c.blend(px+col, top+row, fill)
+					}
+				}
+			}
+		}
+		px += glyphAdvance
+	}
+}
+
+const (
+	glyphHeight  = 7
+	glyphAdvance = 6
+)
+
+// font5x7 растровый шрифт: 7 строк по 5 бит (старший бит = левый пиксель)
+var font5x7 = map[rune][glyphHeight]uint8{
+	'0': {0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	'1': {0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'2': {0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	'3': {0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	'4': {0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	'5': {0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	'6': {0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	'7': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	'9': {0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+	'A': {0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'B': {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'C': {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'D': {0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c},
+	'E': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'F': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10},
+	'G': {0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f},
+	'H': {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'I': {0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'J': {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c},
+	'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f},
+	'M': {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O': {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'P': {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'Q': {0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d},
+	'R': {0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11},
+	'S': {0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e},
+	'T': {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U': {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'V': {0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04},
+	'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a},
+	'X': {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Y': {0x11, 0x11, 0x0a, 0x04, 0x04, 0x04, 0x04},
+	'Z': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f},
+	'.': {0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c},
+	',': {0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08},
+	':': {0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00},
+	'-': {0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00},
+	'+': {0x00, 0x04, 0x04, 0x1f, 0x04, 0x04, 0x00},
+	'/': {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'%': {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'(': {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')': {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'_': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f},
+	'=': {0x00, 0x00, 0x1f, 0x00, 0x1f, 0x00, 0x00},
+	// кириллица (строчные буквы рисуются прописными)
+	'А': {0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'Б': {0x1f, 0x10, 0x10, 0x1e, 0x11, 0x11, 0x1e},
+	'В': {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'Г': {0x1f, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10},
+	'Д': {0x06, 0x0a, 0x0a, 0x0a, 0x0a, 0x1f, 0x11},
+	'Е': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'Ё': {0x0a, 0x1f, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'Ж': {0x15, 0x15, 0x0e, 0x04, 0x0e, 0x15, 0x15},
+	'З': {0x0e, 0x11, 0x01, 0x06, 0x01, 0x11, 0x0e},
+	'И': {0x11, 0x11, 0x13, 0x15, 0x19, 0x11, 0x11},
+	'Й': {0x0a, 0x04, 0x11, 0x13, 0x15, 0x19, 0x11},
+	'К': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'Л': {0x07, 0x09, 0x09, 0x09, 0x09, 0x09, 0x11},
+	'М': {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'Н': {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'О': {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'П': {0x1f, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11},
+	'Р': {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'С': {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'Т': {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'У': {0x11, 0x11, 0x11, 0x0f, 0x01, 0x11, 0x0e},
+	'Ф': {0x04, 0x0e, 0x15, 0x15, 0x15, 0x0e, 0x04},
+	'Х': {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Ц': {0x12, 0x12, 0x12, 0x12, 0x12, 0x1f, 0x01},
+	'Ч': {0x11, 0x11, 0x11, 0x0f, 0x01, 0x01, 0x01},
+	'Ш': {0x15, 0x15, 0x15, 0x15, 0x15, 0x15, 0x1f},
+	'Щ': {0x15, 0x15, 0x15, 0x15, 0x15, 0x1f, 0x01},
+	'Ъ': {0x18, 0x08, 0x08, 0x0e, 0x09, 0x09, 0x0e},
+	'Ы': {0x11, 0x11, 0x11, 0x1d, 0x13, 0x13, 0x1d},
+	'Ь': {0x10, 0x10, 0x10, 0x1e, 0x11, 0x11, 0x1e},
+	'Э': {0x0e, 0x11, 0x01, 0x07, 0x01, 0x11, 0x0e},
+	'Ю': {0x12, 0x15, 0x15, 0x1d, 0x15, 0x15, 0x12},
+	'Я': {0x0f, 0x11, 0x11, 0x0f, 0x05, 0x09, 0x11},
+}
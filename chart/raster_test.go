@@ -0,0 +1,53 @@
+package chart
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestFontCyrillic(t *testing.T) {
+	for _, r := range "АБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯ" {
+		glyph, ok := font5x7[r]
+		if !ok {
+			t.Errorf("нет глифа %c", r)
+			continue
+		}
+		empty := true
+		for _, bits := range glyph {
+			if bits > 0x1f {
+				t.Errorf("глиф %c шире 5 пикселей", r)
+			}
+			if bits != 0 {
+				empty = false
+			}
+		}
+		if empty {
+			t.Errorf("пустой глиф %c", r)
+		}
+	}
+}
+
+// строчные буквы рисуются прописными, неизвестные символы пропускаются
+func TestRasterText(t *testing.T) {
+	ink := color.RGBA{A: 0xff}
+	count := func(s string) int {
+		c := newRasterCanvas(80, 10, Background)
+		c.text(1, 8, s, ink, anchorStart)
+		n := 0
+		for i := 0; i < len(c.img.Pix); i += 4 {
+			if c.img.Pix[i] == 0 {
+				n++
+			}
+		}
+		return n
+	}
+	if count("Сбербанк") == 0 {
+		t.Fatal("кириллица не нарисована")
+	}
+	if count("сбер") != count("СБЕР") {
+		t.Fatal("строчные и прописные буквы различаются")
+	}
+	if count("★") != 0 {
+		t.Fatal("нарисован символ без глифа")
+	}
+}
@@ -2,7 +2,7 @@ package iss
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -27,6 +27,9 @@ const (
 
 var logLevel = &slog.LevelVar{} // INFO
 
+// ErrUnauthorized запрос доступен только с авторизацией (ответ без заголовка X-MicexPassport-Marker: granted)
+var ErrUnauthorized = errors.New("Ошибка HTTP 403 - Доступ запрещен. У вас нет прав на просмотр этого каталога или страницы с использованием предоставленных вами учетных данных")
+
 // SetLogLevel проставим уровень логирования
 func SetLogLevel(level slog.Level) {
 	logLevel.Set(level)
@@ -82,7 +85,10 @@ func (c *Client) callAPI(r *request) (data []byte, err error) {
 	if err != nil {
 		return []byte{}, err
 	}
-	ctx := context.Background()
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	req = req.WithContext(ctx)
 	req.Header = r.header
 
@@ -99,7 +105,8 @@ func (c *Client) callAPI(r *request) (data []byte, err error) {
 		val := resp.Header.Get(autHeaderName)
 		if val != "granted" {
 			// вернем ошибку 403
-			return nil, fmt.Errorf("Ошибка HTTP 403 - Доступ запрещен. У вас нет прав на просмотр этого каталога или страницы с использованием предоставленных вами учетных данны")
+			_ = resp.Body.Close()
+			return nil, ErrUnauthorized
 		}
 
 	}
@@ -144,23 +151,50 @@ func (c *Client) parseRequest(r *request) (err error) {
 	return nil
 }
 
-// getJSON выполним запрос и распарсим ответ в JSON
-func (c *Client) getJSON(r *request, v interface{}) error {
+// getData выполним запрос и распарсим ответ
+// формат ответа (json, xml, csv) определяем по расширению в url запроса
+func (c *Client) getData(r *request, v interface{}) error {
 	var err error
-	const op = "getJSON"
+	const op = "getData"
 
 	body, err := c.callAPI(r)
 	if err != nil {
-		slog.Error("getJSON.callAPI", "err", err.Error())
+		slog.Error("getData.callAPI", "err", err.Error())
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	if err = json.Unmarshal(body, &v); err != nil {
-		slog.Error("getJSON.json.Unmarshal", "err", err.Error())
+	if err = decodeBody(formatFromURL(r.fullURL), body, v); err != nil {
+		slog.Error("getData.decodeBody", "err", err.Error())
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return err
 }
 
+// Get выполним произвольный запрос и распарсим ответ в v
+// v = ссылка на структуру с блоками Table (например Response)
+func (c *Client) Get(req *IssRequest, v interface{}) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	r := &request{
+		method:  http.MethodGet,
+		fullURL: req.URL(),
+	}
+	return c.getData(r, v)
+}
+
+// GetRaw выполним произвольный запрос и вернем ответ сервера как есть
+// (например для архивирования ответов в формате csv)
+func (c *Client) GetRaw(req *IssRequest) ([]byte, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	r := &request{
+		method:  http.MethodGet,
+		fullURL: req.URL(),
+	}
+	return c.callAPI(r)
+}
+
 /*
 Для аутентификации пользователей используется basic-аутентификация.
 и передаются серверу в заголовке запроса на https://passport.moex.com/authenticate
@@ -215,6 +249,13 @@ func WithLogger(logger *slog.Logger) ClientOption {
 	}
 }
 
+// WithHTTPClient свой http клиент (например с таймаутами или для тестов)
+func WithHTTPClient(httpClient HTTPClient) ClientOption {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
 // WithUser установим пользователя
 func WithUser(user string) ClientOption {
 	return func(client *Client) {
@@ -0,0 +1,303 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var boardColumns = []string{"secid", "boardid", "market", "engine", "is_traded", "decimals", "is_primary"}
+
+var candleColumns = []string{"open", "close", "high", "low", "value", "volume", "begin", "end"}
+
+// fakeSber SBER на TQBR: режимы торгов, securities, marketdata, 6 дневных свечей страницами по 2
+func fakeSber(f *fakeISS) {
+	f.handle("securities/SBER.json", static(map[string]Table{
+		"boards": table(boardColumns,
+			[]interface{}{"SBER", "SMAL", "shares", "stock", 1, 2, 0},
+			[]interface{}{"SBER", "TQBR", "shares", "stock", 1, 2, 1},
+		),
+	}))
+	f.handle("engines/stock/markets/shares/boards/TQBR/securities/SBER.json", func(q url.Values) string {
+		return issJSON(map[string]Table{
+			"securities": table([]string{"SECID", "BOARDID", "SHORTNAME", "DECIMALS", "MINSTEP"},
+				[]interface{}{"SBER", "TQBR", "Сбербанк", 2, 0.01}),
+			"marketdata": table([]string{"SECID", "BOARDID", "LAST"},
+				[]interface{}{"SBER", "TQBR", 306.1}),
+		})
+	})
+	f.handle("engines/stock/markets/shares/boards/TQBR/securities/SBER/candleborders.json", static(map[string]Table{
+		"borders": table([]string{"begin", "end", "interval", "board_group_id"},
+			[]interface{}{"2011-12-15 00:00:00", "2024-08-06 00:00:00", 24, 57}),
+	}))
+	f.handle("engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json", func(q url.Values) string {
+		start, _ := strconv.Atoi(q.Get("start"))
+		rows := make([][]interface{}, 0)
+		for i := start; i < start+2 && i < 6; i++ {
+			day := fmt.Sprintf("2024-08-%02d", i+1)
+			rows = append(rows, []interface{}{300.0 + float64(i), 301.0 + float64(i), 302.0, 299.0, 1e9, 1e6, day + " 00:00:00", day + " 23:59:59"})
+		}
+		return issJSON(map[string]Table{"candles": table(candleColumns, rows...)})
+	})
+}
+
+// Client, Ticker и сервисы из нескольких горутин (go test -race)
+func TestConcurrentClient(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	client := newFakeClient(t, f)
+
+	ticker, err := client.GetTicker("SBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 8, 1, 0, 0, 0, 0, TzMsk)
+	service := client.NewCandlesService("stock", "shares", StockBoard, "SBER", Interval_D1, FormatDate(from), "")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 8; i++ {
+		wg.Add(5)
+		go func() {
+			defer wg.Done()
+			info, err := ticker.Info()
+			if err == nil && info.ShortName != "Сбербанк" {
+				err = fmt.Errorf("Info: %+v", info)
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			data, err := ticker.Data()
+			if err == nil && data.Last != 306.1 {
+				err = fmt.Errorf("Data: %+v", data)
+			}
+			errs <- err
+		}()
+		// каждый Do выгружает данные с начала независимо от других
+		go func() {
+			defer wg.Done()
+			candles, err := service.Do()
+			if err == nil && candles.Len() != 6 {
+				err = fmt.Errorf("service.Do: %d свечей", candles.Len())
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			candles, err := ticker.Candles(Interval_D1, from, time.Time{})
+			if err == nil && candles.Len() != 6 {
+				err = fmt.Errorf("ticker.Candles: %d свечей", candles.Len())
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.GetTicker("SBER")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// Next из нескольких горутин: страницы выдаются по очереди без повторов
+func TestConcurrentNext(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	service := newFakeClient(t, f).NewCandlesService("stock", "shares", StockBoard, "SBER", Interval_D1, "2024-08-01", "2024-08-31")
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				data, err := service.Next()
+				if err != nil {
+					if !errors.Is(err, EOF) {
+						t.Error(err)
+					}
+					return
+				}
+				mu.Lock()
+				for _, c := range data {
+					seen[c.Begin]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 6 {
+		t.Fatalf("получено %d свечей, ожидали 6", len(seen))
+	}
+	for begin, n := range seen {
+		if n != 1 {
+			t.Errorf("свеча %s получена %d раз", begin, n)
+		}
+	}
+
+	// Reset начинает выгрузку заново
+	service.Reset()
+	data, err := service.Next()
+	if err != nil || data[0].Begin != "2024-08-01 00:00:00" {
+		t.Fatalf("после Reset: %v %v", data, err)
+	}
+}
+
+// IssRequest не изменяемый: общий запрос можно дополнять из нескольких горутин
+func TestIssRequestCopyOnWrite(t *testing.T) {
+	base := NewIssRequest().Stock().MetaData(false).OnlyMarketData().Columns("marketdata", "SECID", "LAST")
+	want := base.URL()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := base.Start(i).Param("lang", "en").Columns("marketdata", "SECID").Limit(10)
+			if q := req.URL(); q == want {
+				t.Errorf("запрос не изменился: %s", q)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := base.URL(); got != want {
+		t.Fatalf("общий запрос изменился:\n%s\n%s", got, want)
+	}
+
+	// MetaData тоже возвращает копию
+	if meta := base.MetaData(true); meta.URL() == want || base.URL() != want {
+		t.Fatalf("MetaData изменил общий запрос: %s", base.URL())
+	}
+
+	// результат метода построителя без присваивания теряется
+	req := NewIssRequest().Stock()
+	req.From("2024-08-01")
+	if req.dateFrom != "" {
+		t.Fatal("метод построителя изменил исходный запрос")
+	}
+	req = req.From("2024-08-01")
+	if req.dateFrom != "2024-08-01" {
+		t.Fatal("метод построителя не вернул измененную копию")
+	}
+}
+
+// cursorPages страницы по 2 записи из total по параметру start, с блоком <block>.cursor
+func cursorPages(block string, columns []string, total int, row func(i int) []interface{}) fakeHandler {
+	return func(q url.Values) string {
+		start, _ := strconv.Atoi(q.Get("start"))
+		rows := make([][]interface{}, 0)
+		for i := start; i < total && i < start+2; i++ {
+			rows = append(rows, row(i))
+		}
+		return issJSON(map[string]Table{
+			block:             table(columns, rows...),
+			block + ".cursor": table([]string{"INDEX", "TOTAL", "PAGESIZE"}, []interface{}{start, total, 2}),
+		})
+	}
+}
+
+// ProgressFunc вызывается вне блокировки: методы сервиса внутри не зависают
+func TestProgressCallsService(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	f.handle("datashop/algopack/eq/tradestats/SBER.json", cursorPages("data", []string{"SECID", "TRADEDATE", "TRADETIME"}, 5,
+		func(i int) []interface{} { return []interface{}{"SBER", "2024-08-01", fmt.Sprintf("10:%02d:00", i)} }))
+	f.handle("history/engines/futures/markets/options/securities.json", cursorPages("history", []string{"SECID", "TRADEDATE"}, 5,
+		func(i int) []interface{} { return []interface{}{fmt.Sprintf("Si%dBI4", i), "2024-08-01"} }))
+	client := newFakeClient(t, f)
+
+	// не зависает = выгрузка завершается за время теста
+	run := func(t *testing.T, do func() error) {
+		t.Helper()
+		done := make(chan error, 1)
+		go func() { done <- do() }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("взаимная блокировка в ProgressFunc")
+		}
+	}
+
+	t.Run("TradeStatsService", func(t *testing.T) {
+		service := client.NewTradeStatsService(AlgoPackStock, "SBER", "2024-08-01", "2024-08-02", "", false)
+		var calls []int
+		service.Progress(func(fetched, total int) {
+			cursor, ok := service.Cursor()
+			if !ok || cursor.Total != total {
+				t.Errorf("Cursor %+v %v", cursor, ok)
+			}
+			calls = append(calls, fetched)
+		})
+		// Do выгружает копией сервиса, поэтому страницы по Next
+		run(t, func() error {
+			for {
+				if _, err := service.Next(); err != nil {
+					if errors.Is(err, EOF) {
+						return nil
+					}
+					return err
+				}
+			}
+		})
+		if fmt.Sprint(calls) != "[2 4 5]" {
+			t.Errorf("прогресс %v", calls)
+		}
+	})
+	t.Run("OptionHistoryService", func(t *testing.T) {
+		service := client.NewOptionHistoryService("", "2024-08-01", "2024-08-02", "")
+		var calls []int
+		service.Progress(func(fetched, total int) {
+			if cursor, ok := service.Cursor(); !ok || cursor.Total != 5 {
+				t.Errorf("Cursor %+v %v", cursor, ok)
+			}
+			calls = append(calls, fetched)
+		})
+		// Do выгружает копией сервиса, поэтому страницы по Next
+		run(t, func() error {
+			for {
+				if _, err := service.Next(); err != nil {
+					if errors.Is(err, EOF) {
+						return nil
+					}
+					return err
+				}
+			}
+		})
+		if fmt.Sprint(calls) != "[2 4 5]" {
+			t.Errorf("прогресс %v", calls)
+		}
+	})
+	t.Run("CandlesService", func(t *testing.T) {
+		service := client.NewCandlesService("stock", "shares", StockBoard, "SBER", Interval_D1, "2024-08-01", "2024-08-31")
+		calls := 0
+		service.Progress(func(fetched, total int) {
+			// Clone и Reset берут ту же блокировку
+			_ = service.Clone()
+			calls++
+		})
+		run(t, func() error {
+			_, err := service.Next()
+			return err
+		})
+		if calls != 1 {
+			t.Errorf("прогресс %d", calls)
+		}
+	})
+}
@@ -0,0 +1,438 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+/*
+Непрерывный фьючерс: свечи нескольких контрактов одного базового актива (SiM4 -> SiU4 -> SiZ4),
+склеенные в один ряд
+
+контракты берутся из FortsInfo: AssetCode = базовый актив, LastTradeDate = последний торговый день
+(если не заполнен = LastDelDate)
+в каждый момент используется один контракт, переход на следующий определяет RollRule
+разрыв цены в момент перехода убирается сдвигом истории назад (Adjustment):
+цены последнего контракта не меняются, более ранние = корректируются
+*/
+
+// ErrContinuous не удалось построить непрерывный фьючерс
+var ErrContinuous = errors.New("не удалось построить непрерывный фьючерс")
+
+// RollKind способ определения момента перехода на следующий контракт
+type RollKind int
+
+const (
+	RollByExpiry       RollKind = iota // за N дней до последнего торгового дня
+	RollByVolume                       // когда дневной объем следующего контракта больше текущего
+	RollByOpenInterest                 // когда открытый интерес следующего контракта больше текущего
+)
+
+// RollRule правило перехода на следующий контракт
+// для RollByVolume и RollByOpenInterest переход идет со следующего дня после пересечения
+// (в день пересечения значения еще не известны), но не позже экспирации
+type RollRule struct {
+	Kind       RollKind
+	DaysBefore int // RollByExpiry: текущий контракт используется по день LastTradeDate - DaysBefore включительно
+}
+
+// RollDaysBefore переход за n дней до последнего торгового дня
+func RollDaysBefore(n int) RollRule {
+	return RollRule{Kind: RollByExpiry, DaysBefore: n}
+}
+
+// RollOnVolume переход при пересечении дневных объемов
+func RollOnVolume() RollRule {
+	return RollRule{Kind: RollByVolume}
+}
+
+// RollOnOpenInterest переход при пересечении открытого интереса
+func RollOnOpenInterest() RollRule {
+	return RollRule{Kind: RollByOpenInterest}
+}
+
+// Adjustment способ корректировки истории в момент перехода
+type Adjustment int
+
+const (
+	AdjustNone       Adjustment = iota // без корректировки (разрывы цены остаются)
+	AdjustDifference                   // к ценам прошлых контрактов прибавляется разница цен в момент перехода
+	AdjustRatio                        // цены прошлых контрактов умножаются на отношение цен в момент перехода
+)
+
+// RollEvent запись журнала переходов
+type RollEvent struct {
+	Time      time.Time // начало дня, с которого используется новый контракт
+	From      string    // старый контракт
+	To        string    // новый контракт
+	FromPrice float64   // цена закрытия старого контракта перед переходом
+	ToPrice   float64   // цена закрытия нового контракта в тот же момент
+	Gap       float64   // разница (AdjustDifference, AdjustNone) или отношение (AdjustRatio) цен
+}
+
+// FortsHistory итоги торгов по фьючерсу за день (колонки истории фортс совпадают с опционами)
+type FortsHistory = OptionHistory
+
+// GetFortsHistory получить итоги торгов по фьючерсу за период
+func (c *Client) GetFortsHistory(symbol string, from, to time.Time) ([]FortsHistory, error) {
+	s := c.NewOptionHistoryService(symbol, "", "", "")
+	s.issRequest = s.issRequest.Markets("forts").DateRange(from, to)
+	return s.Do()
+}
+
+// GetFortsContracts получить торгуемые контракты по базовому активу (по возрастанию LastTradeDate)
+func (c *Client) GetFortsContracts(assetCode string) ([]FortsInfo, error) {
+	var err error
+	const op = "GetFortsContracts"
+
+	r := &request{
+		method:  http.MethodGet,
+		fullURL: NewIssRequest().Forts().Json().MetaData(false).OnlySecurities().Assets(assetCode).URL(),
+	}
+	var resp Response
+	err = c.getData(r, &resp)
+	if err != nil {
+		slog.Error(op+".getData", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make([]FortsInfo, 0, len(resp.Securities.Data))
+	err = Unmarshal(resp.Securities.Columns, resp.Securities.Data, &result)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// сервер может вернуть и другие активы = отфильтруем
+	result = slices.DeleteFunc(result, func(f FortsInfo) bool {
+		return !strings.EqualFold(f.AssetCode, assetCode)
+	})
+	sortContracts(result)
+	return result, nil
+}
+
+// fortsMonths коды месяцев исполнения в коде контракта фортс (SiM4 = июнь)
+const fortsMonths = "FGHJKMNQUVXZ"
+
+// GetFortsContractsSince получить контракты по базовому активу с последним торговым днем не раньше from:
+// истекшие и торгуемые (по возрастанию LastTradeDate)
+// в списке фортс есть только торгуемые контракты, поэтому истекшие ищутся по коду:
+// префикс торгуемого контракта + месяц + последняя цифра года (Si + M + 4),
+// параметры берутся из описания инструмента /iss/securities/{secid} (один запрос на каждый месяц)
+// код повторяется раз в 10 лет = контракт, у которого месяц экспирации не совпал с кодом, пропускается
+func (c *Client) GetFortsContractsSince(assetCode string, from time.Time) ([]FortsInfo, error) {
+	const op = "GetFortsContractsSince"
+
+	current, err := c.GetFortsContracts(assetCode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var prefix string
+	var first time.Time // экспирация первого торгуемого контракта
+	for _, contract := range current {
+		if p, _, ok := splitContractCode(contract.SecID); ok {
+			prefix, first = p, contractExpiry(contract)
+			break
+		}
+	}
+	if prefix == "" {
+		return nil, fmt.Errorf("%s: %w: %s", op, ErrTickerNotFound, assetCode)
+	}
+
+	known := make(map[string]bool, len(current))
+	for _, contract := range current {
+		known[contract.SecID] = true
+	}
+	result := slices.Clone(current)
+	month := time.Date(from.In(TzMsk).Year(), from.In(TzMsk).Month(), 1, 0, 0, 0, 0, TzMsk)
+	for ; month.Before(first); month = month.AddDate(0, 1, 0) {
+		secid := contractCode(prefix, month)
+		if known[secid] {
+			continue
+		}
+		desc, err := c.GetSecurityDescription(secid)
+		if errors.Is(err, ErrTickerNotFound) {
+			// контракта с таким месяцем не было
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		contract := descriptionContract(desc)
+		expiry := contractExpiry(contract)
+		if !strings.EqualFold(contract.AssetCode, assetCode) || expiry.Year() != month.Year() || expiry.Month() != month.Month() {
+			continue
+		}
+		if expiry.Before(Interval_D1.Truncate(from)) {
+			continue
+		}
+		known[secid] = true
+		result = append(result, contract)
+	}
+	sortContracts(result)
+	return result, nil
+}
+
+// splitContractCode разберем код контракта фортс: SiM4 = Si, июнь, 4
+func splitContractCode(secid string) (prefix string, month time.Month, ok bool) {
+	n := len(secid)
+	if n < 3 || secid[n-1] < '0' || secid[n-1] > '9' {
+		return "", 0, false
+	}
+	i := strings.IndexByte(fortsMonths, secid[n-2])
+	if i < 0 {
+		return "", 0, false
+	}
+	return secid[:n-2], time.Month(i + 1), true
+}
+
+// contractCode код контракта фортс с экспирацией в месяце month: Si + 2024-06 = SiM4
+func contractCode(prefix string, month time.Time) string {
+	return fmt.Sprintf("%s%c%d", prefix, fortsMonths[month.Month()-1], month.Year()%10)
+}
+
+// descriptionContract параметры контракта из описания инструмента
+func descriptionContract(d SecurityDescription) FortsInfo {
+	get := func(name string) string {
+		value, _ := d.Get(name)
+		return value
+	}
+	return FortsInfo{
+		SecID:         d.SecID,
+		BoardID:       FortsBoard,
+		ShortName:     d.ShortName,
+		SecName:       d.Name,
+		LatName:       d.LatName,
+		LastTradeDate: get("LSTTRADE"),
+		LastDelDate:   get("LSTDELDATE"),
+		AssetCode:     get("ASSETCODE"),
+	}
+}
+
+// GetContinuousCandles построить свечи непрерывного фьючерса
+// contracts = контракты одного базового актива (например GetFortsContractsSince)
+// from обязателен, пустой to = сейчас, from > to = ErrContinuous
+// вернем склеенные свечи и журнал переходов
+func (c *Client) GetContinuousCandles(contracts []FortsInfo, interval Interval, from, to time.Time, roll RollRule, adjust Adjustment) (Candles, []RollEvent, error) {
+	const op = "GetContinuousCandles"
+	if from.IsZero() {
+		return Candles{}, nil, fmt.Errorf("%s: %w: не задана дата from", op, ErrContinuous)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.After(to) {
+		return Candles{}, nil, fmt.Errorf("%s: %w: from %s больше to %s", op, ErrContinuous, FormatDate(from), FormatDate(to))
+	}
+	contracts = activeContracts(contracts, from, to, roll)
+	if len(contracts) == 0 {
+		return Candles{}, nil, fmt.Errorf("%s: %w: нет контрактов в диапазоне дат", op, ErrContinuous)
+	}
+
+	// дни перехода: rolls[i] = начало дня, с которого вместо contracts[i] используется contracts[i+1]
+	rolls := make([]time.Time, len(contracts)-1)
+	prev := Interval_D1.Truncate(from)
+	for i := range rolls {
+		day, err := c.rollDay(contracts[i], contracts[i+1], prev, roll)
+		if err != nil {
+			return Candles{}, nil, fmt.Errorf("%s: %w", op, err)
+		}
+		rolls[i], prev = day, day
+	}
+
+	// соберем свечи: по каждому контракту берем его отрезок [rolls[i-1], rolls[i])
+	// цены перехода сравним по последней общей свече перед переходом
+	const overlap = 7 * 24 * time.Hour
+	segments := make([][]Candle, len(contracts))
+	events := make([]RollEvent, 0, len(rolls))
+	var prevData []Candle
+	for i, contract := range contracts {
+		start, end := from, to
+		if i > 0 {
+			start = rolls[i-1]
+		}
+		if i < len(rolls) {
+			end = rolls[i]
+		}
+		candles, err := c.GetFortsCandles(contract.SecID, interval, start.Add(-overlap), end)
+		if err != nil {
+			return Candles{}, nil, fmt.Errorf("%s: %s: %w", op, contract.SecID, err)
+		}
+		segment := make([]Candle, 0, len(candles.Data))
+		for _, candle := range candles.Data {
+			t := candle.Time()
+			if t.Before(start) || (i < len(rolls) && !t.Before(end)) {
+				continue
+			}
+			segment = append(segment, candle)
+		}
+		segments[i] = segment
+
+		if i > 0 {
+			event := RollEvent{Time: start, From: contracts[i-1].SecID, To: contract.SecID}
+			event.FromPrice, event.ToPrice = rollPrices(prevData, candles.Data, start)
+			event.Gap = event.ToPrice - event.FromPrice
+			if adjust == AdjustRatio && event.FromPrice != 0 {
+				event.Gap = event.ToPrice / event.FromPrice
+			}
+			events = append(events, event)
+		}
+		prevData = candles.Data
+	}
+
+	// корректировка: идем от последнего контракта к первому
+	if adjust != AdjustNone {
+		offset, factor := 0.0, 1.0
+		for i := len(segments) - 2; i >= 0; i-- {
+			event := events[i]
+			if adjust == AdjustRatio {
+				if event.FromPrice != 0 {
+					factor *= event.ToPrice / event.FromPrice
+				}
+			} else {
+				offset += event.ToPrice - event.FromPrice
+			}
+			for j := range segments[i] {
+				k := &segments[i][j]
+				if adjust == AdjustRatio {
+					k.Open, k.High, k.Low, k.Close = k.Open*factor, k.High*factor, k.Low*factor, k.Close*factor
+				} else {
+					k.Open, k.High, k.Low, k.Close = k.Open+offset, k.High+offset, k.Low+offset, k.Close+offset
+				}
+			}
+		}
+	}
+
+	result := Candles{
+		Symbol:   contracts[0].AssetCode,
+		Interval: interval.String(),
+		Data:     slices.Concat(segments...),
+	}
+	return result, events, nil
+}
+
+// contractExpiry последний торговый день контракта
+func contractExpiry(f FortsInfo) time.Time {
+	t, _ := ParseDate(f.LastTradeDate)
+	if t.IsZero() {
+		t, _ = ParseDate(f.LastDelDate)
+	}
+	return t
+}
+
+// sortContracts по возрастанию последнего торгового дня
+func sortContracts(contracts []FortsInfo) {
+	slices.SortStableFunc(contracts, func(a, b FortsInfo) int {
+		return contractExpiry(a).Compare(contractExpiry(b))
+	})
+}
+
+// activeContracts контракты, которые могут понадобиться для диапазона [from, to]
+// не нужны контракты, которые перестали использоваться до from, и контракты после того,
+// который еще используется в to (для правил по объему и открытому интересу нужен еще один)
+func activeContracts(contracts []FortsInfo, from, to time.Time, roll RollRule) []FortsInfo {
+	contracts = slices.Clone(contracts)
+	sortContracts(contracts)
+	// день, с которого контракт точно не используется
+	usedUntil := func(f FortsInfo) time.Time {
+		if roll.Kind == RollByExpiry {
+			return contractExpiry(f).AddDate(0, 0, 1-roll.DaysBefore)
+		}
+		return contractExpiry(f).AddDate(0, 0, 1)
+	}
+	result := make([]FortsInfo, 0, len(contracts))
+	extra := 0
+	if roll.Kind != RollByExpiry {
+		extra = 1
+	}
+	for _, contract := range contracts {
+		if contractExpiry(contract).IsZero() || !usedUntil(contract).After(Interval_D1.Truncate(from)) {
+			continue
+		}
+		if n := len(result); n > 0 && usedUntil(result[n-1]).After(to) {
+			if extra == 0 {
+				break
+			}
+			extra--
+		}
+		result = append(result, contract)
+	}
+	return result
+}
+
+// rollDay начало дня, с которого вместо cur используется next (не раньше after)
+func (c *Client) rollDay(cur, next FortsInfo, after time.Time, roll RollRule) (time.Time, error) {
+	expiry := contractExpiry(cur)
+	if roll.Kind == RollByExpiry {
+		return maxTime(after, expiry.AddDate(0, 0, 1-roll.DaysBefore)), nil
+	}
+
+	// дневные итоги по обоим контрактам
+	curHistory, err := c.GetFortsHistory(cur.SecID, after, expiry)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", cur.SecID, err)
+	}
+	nextHistory, err := c.GetFortsHistory(next.SecID, after, expiry)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", next.SecID, err)
+	}
+	metric := func(h FortsHistory) int64 {
+		if roll.Kind == RollByOpenInterest {
+			return h.OpenPosition
+		}
+		return h.Volume
+	}
+	nextByDate := make(map[string]int64, len(nextHistory))
+	for _, h := range nextHistory {
+		nextByDate[h.TradeDate] = metric(h)
+	}
+	for _, h := range curHistory {
+		if nextByDate[h.TradeDate] > metric(h) {
+			day, _ := ParseDate(h.TradeDate)
+			return maxTime(after, day.AddDate(0, 0, 1)), nil
+		}
+	}
+	// пересечения не было = переход после экспирации
+	return maxTime(after, expiry.AddDate(0, 0, 1)), nil
+}
+
+// rollPrices цены закрытия старого и нового контракта на последней общей свече перед переходом
+// если общей свечи нет = последняя свеча старого и первая свеча нового контракта
+func rollPrices(old, next []Candle, roll time.Time) (float64, float64) {
+	closes := make(map[string]float64, len(next))
+	for _, k := range next {
+		if k.Time().Before(roll) {
+			closes[k.Begin] = k.Close
+		}
+	}
+	var fromPrice float64
+	for i := len(old) - 1; i >= 0; i-- {
+		if !old[i].Time().Before(roll) {
+			continue
+		}
+		if fromPrice == 0 {
+			fromPrice = old[i].Close
+		}
+		if toPrice, ok := closes[old[i].Begin]; ok {
+			return old[i].Close, toPrice
+		}
+	}
+	for _, k := range next {
+		if !k.Time().Before(roll) {
+			return fromPrice, k.Close
+		}
+	}
+	return fromPrice, fromPrice
+}
+
+// maxTime большее из двух времен
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,255 @@
+package iss
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var fortsColumns = []string{"SECID", "BOARDID", "SHORTNAME", "LASTTRADEDATE", "LASTDELDATE", "ASSETCODE"}
+
+var descriptionColumns = []string{"name", "title", "value", "type", "sort_order", "is_hidden", "precision"}
+
+// fakeContract описание контракта фортс: /iss/securities/{secid}
+func fakeContract(f *fakeISS, secid, assetCode, lastTrade string) {
+	f.handle("securities/"+secid+".json", static(map[string]Table{
+		"description": table(descriptionColumns,
+			[]interface{}{"SECID", "Код ценной бумаги", secid, "string", 1, 0, nil},
+			[]interface{}{"SHORTNAME", "Краткое наименование", secid, "string", 2, 0, nil},
+			[]interface{}{"LSTTRADE", "Последний торговый день", lastTrade, "date", 3, 0, nil},
+			[]interface{}{"LSTDELDATE", "Дата исполнения", lastTrade, "date", 4, 0, nil},
+			[]interface{}{"ASSETCODE", "Код базового актива", assetCode, "string", 5, 0, nil},
+		),
+		"boards": table(boardColumns,
+			[]interface{}{secid, "RFUD", "forts", "futures", 0, 0, 1}),
+	}))
+}
+
+func TestGetFortsContractsSince(t *testing.T) {
+	f := newFakeISS()
+	// торгуемые контракты (сервер вернул и чужой актив)
+	f.handle("engines/futures/markets/forts/securities.json", static(map[string]Table{
+		"securities": table(fortsColumns,
+			[]interface{}{"SiH5", "RFUD", "Si-3.25", "2025-03-20", "2025-03-20", "Si"},
+			[]interface{}{"SiZ4", "RFUD", "Si-12.24", "2024-12-19", "2024-12-19", "Si"},
+			[]interface{}{"EuZ4", "RFUD", "Eu-12.24", "2024-12-19", "2024-12-19", "Eu"},
+		),
+	}))
+	// истекшие
+	fakeContract(f, "SiH4", "Si", "2024-03-21")
+	fakeContract(f, "SiM4", "Si", "2024-06-20")
+	fakeContract(f, "SiU4", "Si", "2024-09-19")
+	// код повторился: контракт 10 лет назад
+	fakeContract(f, "SiZ3", "Si", "2013-12-16")
+	// контракт другого актива с тем же кодом
+	fakeContract(f, "SiF4", "SBRF", "2024-01-18")
+	// остальные месяцы: пустое описание
+	f.handle("securities/SiG4.json", static(map[string]Table{"description": table(descriptionColumns)}))
+	client := newFakeClient(t, f)
+
+	// SiH4 истек раньше from = не нужен
+	contracts, err := client.GetFortsContractsSince("Si", time.Date(2023, 12, 1, 0, 0, 0, 0, TzMsk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range contracts {
+		got = append(got, c.SecID)
+	}
+	want := []string{"SiH4", "SiM4", "SiU4", "SiZ4", "SiH5"}
+	if len(got) != len(want) {
+		t.Fatalf("контракты %v, ожидали %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("контракты %v, ожидали %v", got, want)
+		}
+	}
+	if c := contracts[1]; c.LastTradeDate != "2024-06-20" || c.AssetCode != "Si" || c.BoardID != FortsBoard {
+		t.Errorf("SiM4: %+v", c)
+	}
+	// по одному запросу описания на месяц до первого торгуемого контракта
+	if n := f.count("securities/SiM4.json"); n != 1 {
+		t.Errorf("запросов SiM4: %d", n)
+	}
+	if n := f.count("securities/SiZ4.json"); n != 0 {
+		t.Errorf("торгуемый контракт запрошен %d раз", n)
+	}
+
+	// from после экспирации SiH4
+	contracts, err = client.GetFortsContractsSince("Si", time.Date(2024, 3, 22, 0, 0, 0, 0, TzMsk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) != 4 || contracts[0].SecID != "SiM4" {
+		t.Fatalf("контракты %+v", contracts)
+	}
+
+	// нет торгуемых контрактов = префикс кода неизвестен
+	if _, err := client.GetFortsContractsSince("RI", time.Time{}); err == nil {
+		t.Fatal("нет ошибки для актива без торгуемых контрактов")
+	}
+}
+
+func TestContractCode(t *testing.T) {
+	tests := []struct {
+		secid  string
+		prefix string
+		month  time.Month
+		ok     bool
+	}{
+		{"SiM4", "Si", time.June, true},
+		{"GZZ5", "GZ", time.December, true},
+		{"BRF5", "BR", time.January, true},
+		{"USDRUBF", "", 0, false},
+		{"SiA4", "", 0, false},
+		{"Z4", "", 0, false},
+	}
+	for _, tt := range tests {
+		prefix, month, ok := splitContractCode(tt.secid)
+		if prefix != tt.prefix || month != tt.month || ok != tt.ok {
+			t.Errorf("splitContractCode(%s) = %s %v %v", tt.secid, prefix, month, ok)
+		}
+		if tt.ok {
+			if code := contractCode(prefix, time.Date(2024, month, 1, 0, 0, 0, 0, TzMsk)); code[:len(code)-1] != tt.secid[:len(tt.secid)-1] {
+				t.Errorf("contractCode(%s, %v) = %s", prefix, month, code)
+			}
+		}
+	}
+}
+
+// история фортс по контракту: колонки TRADEDATE, VOLUME, OPENPOSITION
+func fakeFortsHistory(f *fakeISS, secid string, rows ...[]interface{}) {
+	f.handle("history/engines/futures/markets/forts/securities/"+secid+".json", func(q url.Values) string {
+		return issJSON(map[string]Table{
+			"history": table([]string{"TRADEDATE", "SECID", "BOARDID", "VOLUME", "OPENPOSITION"}, rows...),
+			"history.cursor": table([]string{"INDEX", "TOTAL", "PAGESIZE"},
+				[]interface{}{0, len(rows), 100}),
+		})
+	})
+}
+
+func TestRollDay(t *testing.T) {
+	f := newFakeISS()
+	// объем SiU4 больше SiM4 с 2024-06-11, открытый интерес = с 2024-06-13
+	fakeFortsHistory(f, "SiM4",
+		[]interface{}{"2024-06-10", "SiM4", "RFUD", 900, 500},
+		[]interface{}{"2024-06-11", "SiM4", "RFUD", 800, 400},
+		[]interface{}{"2024-06-13", "SiM4", "RFUD", 700, 300},
+	)
+	fakeFortsHistory(f, "SiU4",
+		[]interface{}{"2024-06-10", "SiU4", "RFUD", 100, 100},
+		[]interface{}{"2024-06-11", "SiU4", "RFUD", 850, 200},
+		[]interface{}{"2024-06-13", "SiU4", "RFUD", 950, 350},
+	)
+	// объем и открытый интерес SiZ4 не пересекли SiU4
+	fakeFortsHistory(f, "SiZ4")
+	client := newFakeClient(t, f)
+
+	siM4 := FortsInfo{SecID: "SiM4", LastTradeDate: "2024-06-20"}
+	siU4 := FortsInfo{SecID: "SiU4", LastTradeDate: "2024-09-19"}
+	siZ4 := FortsInfo{SecID: "SiZ4", LastTradeDate: "2024-12-19"}
+	day := func(s string) time.Time {
+		t, _ := ParseDate(s)
+		return t
+	}
+	tests := []struct {
+		name      string
+		cur, next FortsInfo
+		after     string
+		roll      RollRule
+		want      string
+	}{
+		{"экспирация", siM4, siU4, "2024-06-01", RollDaysBefore(0), "2024-06-21"},
+		{"за 5 дней", siM4, siU4, "2024-06-01", RollDaysBefore(5), "2024-06-16"},
+		{"не раньше after", siM4, siU4, "2024-06-18", RollDaysBefore(5), "2024-06-18"},
+		{"объем", siM4, siU4, "2024-06-01", RollOnVolume(), "2024-06-12"},
+		{"открытый интерес", siM4, siU4, "2024-06-01", RollOnOpenInterest(), "2024-06-14"},
+		{"объем после after", siM4, siU4, "2024-06-13", RollOnVolume(), "2024-06-13"},
+		{"без пересечения", siU4, siZ4, "2024-06-01", RollOnVolume(), "2024-09-20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.rollDay(tt.cur, tt.next, day(tt.after), tt.roll)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(day(tt.want)) {
+				t.Fatalf("rollDay = %s, ожидали %s", FormatDate(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRollPrices(t *testing.T) {
+	candle := func(begin string, close float64) Candle {
+		return Candle{Close: close, Begin: begin + " 00:00:00"}
+	}
+	roll, _ := ParseDate("2024-06-14")
+	tests := []struct {
+		name      string
+		old, next []Candle
+		from, to  float64
+	}{
+		{
+			name: "последняя общая свеча",
+			old:  []Candle{candle("2024-06-11", 90), candle("2024-06-12", 91), candle("2024-06-13", 92), candle("2024-06-14", 93)},
+			next: []Candle{candle("2024-06-11", 95), candle("2024-06-12", 96), candle("2024-06-14", 98)},
+			from: 91, to: 96,
+		},
+		{
+			name: "нет общей свечи",
+			old:  []Candle{candle("2024-06-12", 91), candle("2024-06-13", 92)},
+			next: []Candle{candle("2024-06-14", 98), candle("2024-06-17", 99)},
+			from: 92, to: 98,
+		},
+		{
+			name: "нет свечей нового контракта",
+			old:  []Candle{candle("2024-06-13", 92)},
+			from: 92, to: 92,
+		},
+		{
+			name: "нет свечей",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := rollPrices(tt.old, tt.next, roll)
+			if from != tt.from || to != tt.to {
+				t.Fatalf("rollPrices = %v %v, ожидали %v %v", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+// пустой from и from > to = ErrContinuous без запросов к серверу
+func TestGetContinuousCandlesDates(t *testing.T) {
+	f := newFakeISS()
+	client := newFakeClient(t, f)
+	contracts := []FortsInfo{
+		{SecID: "SiU4", AssetCode: "Si", LastTradeDate: "2024-09-19"},
+		{SecID: "SiZ4", AssetCode: "Si", LastTradeDate: "2024-12-19"},
+	}
+	day := time.Date(2024, 8, 1, 0, 0, 0, 0, TzMsk)
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"пустой from", time.Time{}, day},
+		{"пустой from и to", time.Time{}, time.Time{}},
+		{"from > to", day.AddDate(0, 0, 1), day},
+		{"from в будущем, пустой to", time.Now().AddDate(0, 0, 1), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := client.GetContinuousCandles(contracts, Interval_D1, tt.from, tt.to, RollRule{}, AdjustNone)
+			if !errors.Is(err, ErrContinuous) {
+				t.Fatalf("ошибка %v, ожидали ErrContinuous", err)
+			}
+		})
+	}
+	if len(f.requests) != 0 {
+		t.Fatalf("запросы %v", f.requests)
+	}
+}
@@ -0,0 +1,85 @@
+package iss
+
+/*
+Многие запросы ISS (history, securities, algopack) выдают данные постранично
+и дополнительно возвращают блок <block>.cursor
+
+"history.cursor": {
+	"columns": ["INDEX", "TOTAL", "PAGESIZE"],
+	"data": [[0, 1354, 100]]
+}
+
+INDEX = номер первой записи на странице
+TOTAL = всего записей
+PAGESIZE = размер страницы
+*/
+
+// Cursor параметры постраничной выдачи (блок <block>.cursor)
+type Cursor struct {
+	Index    int `json:"INDEX"`    // номер первой записи на текущей странице
+	Total    int `json:"TOTAL"`    // всего записей
+	PageSize int `json:"PAGESIZE"` // размер страницы
+}
+
+// Next номер первой записи следующей страницы
+func (c Cursor) Next() int {
+	return c.Index + c.PageSize
+}
+
+// HasNext есть ли еще данные
+func (c Cursor) HasNext() bool {
+	return c.Next() < c.Total
+}
+
+// ProgressFunc функция обратного вызова для отображения прогресса выгрузки
+// fetched = сколько записей уже получено
+// total = сколько всего записей (0 = если сервер не сообщает общее количество)
+// вызывается после получения страницы вне блокировки сервиса: внутри можно вызывать методы сервиса (Cursor, Reset ...)
+type ProgressFunc func(fetched, total int)
+
+// parseCursor распарсим блок <block>.cursor
+// если блока нет (или он пустой) вернем false
+func parseCursor(columns []string, data [][]interface{}) (Cursor, bool) {
+	if len(data) == 0 {
+		return Cursor{}, false
+	}
+	list := make([]Cursor, 0, len(data))
+	err := Unmarshal(columns, data, &list)
+	if err != nil || len(list) == 0 {
+		return Cursor{}, false
+	}
+	return list[0], true
+}
+
+// pageState состояние постраничной выгрузки сервиса
+type pageState struct {
+	start   int     // номер первой записи следующей страницы
+	cursor  *Cursor // последний полученный курсор
+	fetched int     // сколько записей уже получено
+}
+
+// done по курсору видно что данных больше нет
+func (p *pageState) done() bool {
+	return p.cursor != nil && !p.cursor.HasNext()
+}
+
+// update учтем полученную страницу из count записей
+// если пришел курсор = следующая страница начинается после текущей
+func (p *pageState) update(count int, cursor Cursor, ok bool) {
+	p.fetched += count
+	if ok {
+		p.cursor = &cursor
+		p.start = cursor.Next()
+		return
+	}
+	// увеличим параметр start на кол-во полученных данных
+	p.start += count
+}
+
+// total сколько всего записей (0 = неизвестно)
+func (p *pageState) total() int {
+	if p.cursor == nil {
+		return 0
+	}
+	return p.cursor.Total
+}
@@ -0,0 +1,53 @@
+package iss
+
+import "testing"
+
+func TestPageStateCursor(t *testing.T) {
+	var p pageState
+	if p.done() {
+		t.Fatal("done до первой страницы")
+	}
+	// страницы по 100 записей из 250
+	pages := []struct {
+		cursor Cursor
+		count  int
+		start  int
+		done   bool
+	}{
+		{Cursor{Index: 0, Total: 250, PageSize: 100}, 100, 100, false},
+		{Cursor{Index: 100, Total: 250, PageSize: 100}, 100, 200, false},
+		{Cursor{Index: 200, Total: 250, PageSize: 100}, 50, 300, true},
+	}
+	for i, page := range pages {
+		p.update(page.count, page.cursor, true)
+		if p.start != page.start || p.done() != page.done {
+			t.Fatalf("страница %d: start=%d done=%v, ожидали start=%d done=%v", i, p.start, p.done(), page.start, page.done)
+		}
+	}
+	if p.fetched != 250 || p.total() != 250 {
+		t.Fatalf("fetched=%d total=%d", p.fetched, p.total())
+	}
+}
+
+func TestPageStateWithoutCursor(t *testing.T) {
+	var p pageState
+	p.update(500, Cursor{}, false)
+	p.update(120, Cursor{}, false)
+	if p.start != 620 || p.done() || p.total() != 0 {
+		t.Fatalf("start=%d done=%v total=%d", p.start, p.done(), p.total())
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	columns := []string{"INDEX", "TOTAL", "PAGESIZE"}
+	c, ok := parseCursor(columns, [][]interface{}{{0.0, 1354.0, 100.0}})
+	if !ok || c != (Cursor{Index: 0, Total: 1354, PageSize: 100}) {
+		t.Fatalf("cursor=%+v ok=%v", c, ok)
+	}
+	if !c.HasNext() || c.Next() != 100 {
+		t.Fatalf("HasNext=%v Next=%d", c.HasNext(), c.Next())
+	}
+	if _, ok := parseCursor(columns, nil); ok {
+		t.Fatal("пустой блок должен вернуть false")
+	}
+}
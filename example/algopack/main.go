@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"time"
 )
 
 func init() {
@@ -28,9 +29,9 @@ func main() {
 	iss.SetLogLevel(slog.LevelDebug)
 
 	// если не указать дату = то данные за последний день
-	//oi, err := client.GetFutOIAll("", 1)
+	//oi, err := client.GetFutOIAll(time.Time{}, 1)
 	// latest =1 возвращает последнюю пятиминутку за указанную дату
-	//oi, err := client.GetFutOIAll("2024-08-09", 1)
+	//oi, err := client.GetFutOIAll(time.Date(2024, 8, 9, 0, 0, 0, 0, iss.TzMsk), 1)
 	//if err != nil {
 	//	slog.Error("main", "ошибка GetFutOIAll", err.Error())
 	//}
@@ -42,7 +43,7 @@ func main() {
 	//}
 
 	// по заданному тикеру
-	oi, err := client.GetFutOI("Si", "2024-08-01", "2024-09-01", 0)
+	oi, err := client.GetFutOI("Si", time.Date(2024, 8, 1, 0, 0, 0, 0, iss.TzMsk), time.Date(2024, 9, 1, 0, 0, 0, 0, iss.TzMsk), 0)
 	if err != nil {
 		slog.Error("main", "ошибка GetFutOI", err.Error())
 	}
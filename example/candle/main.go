@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"time"
 )
 
 func main() {
@@ -34,9 +35,9 @@ func main() {
 	}
 
 	// по акциям
-	candles, err := client.GetStockCandles("SBER", interval, "2024-08-01", "2025-01-01")
+	candles, err := client.GetStockCandles("SBER", interval, time.Date(2024, 8, 1, 0, 0, 0, 0, iss.TzMsk), time.Now())
 	// по фючерсам
-	//candles, err := client.GetFortsCandles("SiU4", iss.Interval_M1, "2024-08-09  23:00:00", "2025-01-01")
+	//candles, err := client.GetFortsCandles("SiU4", iss.Interval_M1, time.Date(2024, 8, 9, 23, 0, 0, 0, iss.TzMsk), time.Now())
 	if err != nil {
 		slog.Error("main", "ошибка GetCandles", err.Error())
 		return
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+	"github.com/Ruvad39/go-moex-iss/chart"
+	"github.com/Ruvad39/go-moex-iss/indicators"
+)
+
+func main() {
+	// создание клиента
+	client, err := iss.NewClient()
+	if err != nil {
+		slog.Error("main", "NewClient", err.Error())
+		return
+	}
+
+	from := time.Now().AddDate(0, 0, -3)
+	candles, err := client.GetStockCandles("SBER", iss.Interval_M10, from, time.Now())
+	if err != nil {
+		slog.Error("main", "GetStockCandles", err.Error())
+		return
+	}
+
+	bars := indicators.FromCandles(candles)
+	closes := indicators.Closes(bars)
+	c := chart.New(candles, chart.WithSessions(iss.MarketSessions(iss.MarketShares)...))
+	c.AddLine("SMA 20", indicators.CalcSMA(closes, 20), chart.Blue)
+	c.AddLine("VWAP", indicators.CalcVWAP(bars), chart.Orange)
+
+	file, err := os.Create("sber_m10.png")
+	if err != nil {
+		slog.Error("main", "Create", err.Error())
+		return
+	}
+	defer file.Close()
+	if err = c.PNG(file); err != nil {
+		slog.Error("main", "PNG", err.Error())
+		return
+	}
+	slog.Info("chart", "file", file.Name(), "candles", candles.Len())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+// пример одновременной работы с одним клиентом, тикером и сервисом из нескольких горутин
+// проверка на гонки: go run -race ./example/concurrent
+func main() {
+	client, err := iss.NewClient()
+	if err != nil {
+		slog.Error("main", "NewClient", err.Error())
+		return
+	}
+
+	ticker, err := client.GetTicker("SBER")
+	if err != nil {
+		slog.Error("main", "ошибка GetTicker", err.Error())
+		return
+	}
+
+	from := time.Date(2024, 8, 1, 0, 0, 0, 0, iss.TzMsk)
+	service := client.NewCandlesService("stock", "shares", iss.StockBoard, "SBER", iss.Interval_D1, iss.FormatDate(from), "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(4)
+		// Info и Data не меняют запрос тикера
+		go func() {
+			defer wg.Done()
+			info, err := ticker.Info()
+			if err != nil {
+				slog.Error("ticker.Info", "err", err.Error())
+				return
+			}
+			slog.Info("ticker.Info", "SecID", info.SecID, "Decimals", info.Decimals)
+		}()
+		go func() {
+			defer wg.Done()
+			data, err := ticker.Data()
+			if err != nil {
+				slog.Error("ticker.Data", "err", err.Error())
+				return
+			}
+			slog.Info("ticker.Data", "SecID", data.SecID, "Last", data.Last)
+		}()
+		// каждый Do выгружает данные с начала независимо от других
+		go func() {
+			defer wg.Done()
+			candles, err := service.Do()
+			if err != nil {
+				slog.Error("service.Do", "err", err.Error())
+				return
+			}
+			slog.Info("service.Do", "len(candles)", candles.Len())
+		}()
+		go func() {
+			defer wg.Done()
+			candles, err := ticker.Candles(iss.Interval_D1, from, time.Time{})
+			if err != nil {
+				slog.Error("ticker.Candles", "err", err.Error())
+				return
+			}
+			slog.Info("ticker.Candles", "len(candles)", candles.Len())
+		}()
+	}
+	wg.Wait()
+
+	// Next из нескольких горутин: страницы выдаются по очереди без повторов
+	service.Reset()
+	var mu sync.Mutex
+	total := 0
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				data, err := service.Next()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				total += len(data)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	slog.Info("service.Next", "всего свечей", total)
+}
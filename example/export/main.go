@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+	"github.com/Ruvad39/go-moex-iss/export"
+)
+
+func main() {
+	// создание клиента
+	client, err := iss.NewClient()
+	if err != nil {
+		slog.Error("main", "NewClient", err.Error())
+		return
+	}
+
+	candles, err := client.GetStockCandles("SBER", iss.Interval_D1, time.Date(2024, 1, 1, 0, 0, 0, 0, iss.TzMsk), time.Now())
+	if err != nil {
+		slog.Error("main", "GetStockCandles", err.Error())
+		return
+	}
+
+	// CSV для Excel: разделитель ';' и десятичная запятая
+	file, err := os.Create("sber_d1.csv")
+	if err != nil {
+		slog.Error("main", "Create", err.Error())
+		return
+	}
+	defer file.Close()
+	w := export.NewCSVWriter(file, export.WithDelimiter(';'), export.WithDecimalComma())
+	if err = w.Write(candles.Data); err != nil {
+		slog.Error("main", "csv.Write", err.Error())
+		return
+	}
+	if err = w.Close(); err != nil {
+		slog.Error("main", "csv.Close", err.Error())
+		return
+	}
+
+	// постраничная выгрузка супер свечей в parquet
+	pqFile, err := os.Create("sber_tradestats.parquet")
+	if err != nil {
+		slog.Error("main", "Create", err.Error())
+		return
+	}
+	defer pqFile.Close()
+	service := client.NewTradeStatsService(iss.AlgoPackStock, "SBER", "2024-06-01", "2024-06-30", "", false)
+	pq := export.NewParquetWriter(pqFile)
+	n, err := export.Stream(pq, service.Next)
+	if err != nil {
+		slog.Error("main", "Stream", err.Error())
+		return
+	}
+	if err = pq.Close(); err != nil {
+		slog.Error("main", "parquet.Close", err.Error())
+		return
+	}
+	slog.Info("export", "csv", candles.Len(), "parquet", n)
+}
@@ -4,6 +4,7 @@ import (
 	iss "github.com/Ruvad39/go-moex-iss"
 	"log/slog"
 	"strconv"
+	"time"
 )
 
 func main() {
@@ -15,13 +16,13 @@ func main() {
 	iss.SetLogLevel(slog.LevelDebug)
 
 	// по одному символу
-	history, err := client.GetOptionHistory("Si88000BH4E", "2024-08-22", "")
+	history, err := client.GetOptionHistory("Si88000BH4E", time.Date(2024, 8, 22, 0, 0, 0, 0, iss.TzMsk), time.Time{})
 	//if err != nil {
 	//	slog.Error("main", "ошибкаGetOptionHistory", err.Error())
 	//	return
 	//}
 
-	//history, err := client.GetOptionHistoryAllDate("2024-08-01")
+	//history, err := client.GetOptionHistoryAllDate(time.Date(2024, 8, 1, 0, 0, 0, 0, iss.TzMsk))
 	if err != nil {
 		slog.Error("main", "ошибка GetOptionHistory", err.Error())
 		return
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+	"github.com/Ruvad39/go-moex-iss/indicators"
+)
+
+func main() {
+	// создание клиента
+	client, err := iss.NewClient()
+	if err != nil {
+		slog.Error("main", "NewClient", err.Error())
+		return
+	}
+
+	candles, err := client.GetStockCandles("SBER", iss.Interval_D1, time.Date(2024, 1, 1, 0, 0, 0, 0, iss.TzMsk), time.Now())
+	if err != nil {
+		slog.Error("main", "GetStockCandles", err.Error())
+		return
+	}
+
+	// пакетный расчет по всему ряду (период разогрева = NaN)
+	bars := indicators.FromCandles(candles)
+	closes := indicators.Closes(bars)
+	sma := indicators.CalcSMA(closes, 20)
+	rsi := indicators.CalcRSI(closes, 14)
+	atr := indicators.CalcATR(bars, 14)
+	bb := indicators.CalcBollinger(closes, 20, 2)
+	last := len(bars) - 1
+	slog.Info("SBER",
+		"time", bars[last].Time,
+		"close", closes[last],
+		"sma20", sma[last],
+		"rsi14", rsi[last],
+		"atr14", atr[last],
+		"bb.upper", bb[last].Upper,
+		"bb.lower", bb[last].Lower,
+	)
+
+	// потоковый расчет по одному значению
+	macd, err := indicators.NewMACD(12, 26, 9)
+	if err != nil {
+		slog.Error("main", "NewMACD", err.Error())
+		return
+	}
+	for _, bar := range bars {
+		if v, ok := macd.Update(bar.Close); ok {
+			slog.Info("MACD", "time", bar.Time, "macd", v.MACD, "signal", v.Signal, "hist", v.Hist)
+		}
+	}
+
+	// супер свечи TradeStats
+	stats, err := client.GetStockTradeStats("SBER", time.Now(), time.Now(), false)
+	if err != nil {
+		slog.Error("main", "GetStockTradeStats", err.Error())
+		return
+	}
+	vwap := indicators.CalcVWAP(indicators.FromTradeStats(stats))
+	if len(vwap) > 0 {
+		slog.Info("SBER", "vwap", vwap[len(vwap)-1])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	iss "github.com/Ruvad39/go-moex-iss"
 	"log/slog"
 	"strconv"
+	"time"
 )
 
 func main() {
@@ -41,7 +42,7 @@ func main() {
 	slog.Info("ticker.Info", slog.Any("t_data", data))
 
 	// свечи
-	candles, err := ticker.Candles(iss.Interval_D1, "2024-08-01", "2025-01-01")
+	candles, err := ticker.Candles(iss.Interval_D1, time.Date(2024, 8, 1, 0, 0, 0, 0, iss.TzMsk), time.Now())
 	if err != nil {
 		slog.Error("main", "ошибка Candles", err.Error())
 		return
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"time"
 )
 
 func init() {
@@ -27,11 +28,11 @@ func main() {
 	}
 	//iss.SetLogLevel(slog.LevelDebug)
 
-	stats, err := client.GetStockTradeStats("SBER", "2024-09-01", "2024-09-03", false)
-	//stats, err := client.GetStockTradeStatsAll("2024-09-03", false)
-	//stats, err := client.GetFortsTradeStats("SiU4", "2024-09-01", "2024-09-03", false)
-	//stats, err := client.GetFortsTradeStatsAll("2024-09-03", false)
-	//stats, err := client.GetFxTradeStatsAll("2024-09-03", false)
+	stats, err := client.GetStockTradeStats("SBER", time.Date(2024, 9, 1, 0, 0, 0, 0, iss.TzMsk), time.Date(2024, 9, 3, 0, 0, 0, 0, iss.TzMsk), false)
+	//stats, err := client.GetStockTradeStatsAll(time.Date(2024, 9, 3, 0, 0, 0, 0, iss.TzMsk), false)
+	//stats, err := client.GetFortsTradeStats("SiU4", time.Date(2024, 9, 1, 0, 0, 0, 0, iss.TzMsk), time.Date(2024, 9, 3, 0, 0, 0, 0, iss.TzMsk), false)
+	//stats, err := client.GetFortsTradeStatsAll(time.Date(2024, 9, 3, 0, 0, 0, 0, iss.TzMsk), false)
+	//stats, err := client.GetFxTradeStatsAll(time.Date(2024, 9, 3, 0, 0, 0, 0, iss.TzMsk), false)
 
 	if err != nil {
 		slog.Error("main", "ошибка GetTradeStats", err.Error())
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CSVWriter запись в формате CSV
+type CSVWriter struct {
+	w            *csv.Writer
+	decimalComma bool
+	header       bool
+	schema       *schema
+	record       []string
+}
+
+// CSVOption параметры CSVWriter
+type CSVOption func(*CSVWriter)
+
+// WithDelimiter разделитель колонок (по умолчанию ',')
+func WithDelimiter(delimiter rune) CSVOption {
+	return func(w *CSVWriter) {
+		w.w.Comma = delimiter
+	}
+}
+
+// WithDecimalComma дробная часть через запятую (для Excel с русской локалью, обычно вместе с WithDelimiter(';'))
+func WithDecimalComma() CSVOption {
+	return func(w *CSVWriter) {
+		w.decimalComma = true
+	}
+}
+
+// WithoutHeader не писать строку с названиями колонок
+func WithoutHeader() CSVOption {
+	return func(w *CSVWriter) {
+		w.header = false
+	}
+}
+
+// NewCSVWriter запись CSV в w
+func NewCSVWriter(w io.Writer, opts ...CSVOption) *CSVWriter {
+	c := &CSVWriter{w: csv.NewWriter(w), header: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Write записать срез структур
+func (c *CSVWriter) Write(rows interface{}) error {
+	first := c.schema == nil
+	v, err := sliceOf(rows, &c.schema)
+	if err != nil {
+		return err
+	}
+	if first && c.header {
+		header := make([]string, len(c.schema.columns))
+		for i, col := range c.schema.columns {
+			header[i] = col.name
+		}
+		if err = c.w.Write(header); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		c.record = c.record[:0]
+		for _, col := range c.schema.columns {
+			value, ok := col.value(row)
+			if !ok {
+				c.record = append(c.record, "")
+				continue
+			}
+			c.record = append(c.record, c.format(value))
+		}
+		if err = c.w.Write(c.record); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// format значение поля в строку
+func (c *CSVWriter) format(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		s := strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+		if c.decimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	}
+	return ""
+}
+
+// Close дописать буферизованные данные
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
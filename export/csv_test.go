@@ -0,0 +1,40 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	last := 306.25
+	rows := []struct {
+		SecID string   `json:"SECID"`
+		Close float64  `json:"CLOSE"`
+		Last  *float64 `json:"LAST"`
+		Lot   int      `json:"LOTSIZE"`
+	}{
+		{"SBER", 306.1, &last, 10},
+		{"GAZP", 130.5, nil, 10},
+	}
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, WithDelimiter(';'), WithDecimalComma())
+	if err := w.Write(rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "SECID;CLOSE;LAST;LOTSIZE\nSBER;306,1;306,25;10\nGAZP;130,5;;10\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("csv:\n%s\nожидали:\n%s", got, want)
+	}
+
+	var jsonl bytes.Buffer
+	j := NewJSONLWriter(&jsonl)
+	if err := j.Write(rows[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := jsonl.String(), `{"SECID":"GAZP","CLOSE":130.5,"LAST":null,"LOTSIZE":10}`+"\n"; got != want {
+		t.Fatalf("jsonl: %s", got)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Package export выгрузка срезов структур iss (Candle, TradeStats, FutOI, StockData ...) в файлы
+для pandas, ClickHouse и других систем
+
+форматы: CSV (разделитель и десятичная запятая настраиваются), JSON Lines и Apache Parquet
+
+колонки = экспортируемые поля структуры, название колонки = json таг (как в ответе iss)
+поддерживаются поля string, bool, int*, uint*, float* и ссылки на них (*float64 ...):
+nil = пустое значение (CSV = пустая строка, JSON Lines = null, Parquet = null в OPTIONAL колонке)
+
+данные постраничных сервисов можно выгружать по одной странице, не держа все в памяти:
+
+	w := export.NewParquetWriter(file)
+	n, err := export.Stream(w, service.Next)
+	err = w.Close()
+*/
+package export
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+// ErrType не поддерживаемый тип данных
+var ErrType = errors.New("не поддерживаемый тип данных")
+
+// Writer запись срезов структур в выбранном формате
+type Writer interface {
+	// Write записать срез структур (например []iss.Candle, []iss.TradeStats)
+	// все вызовы должны передавать один и тот же тип
+	Write(rows interface{}) error
+	// Close дописать буферизованные данные (исходный io.Writer не закрывается)
+	Close() error
+}
+
+// Stream выгрузим данные постранично: next = метод Next сервиса
+// (CandlesService.Next, TradeStatsService.Next, OptionHistoryService.Next ...)
+// в памяти хранится только одна страница, вернем количество записанных строк
+func Stream[T any](w Writer, next func() ([]T, error)) (int, error) {
+	total := 0
+	for {
+		rows, err := next()
+		// как io.Reader: данные вместе с EOF тоже запишем
+		if len(rows) > 0 {
+			if werr := w.Write(rows); werr != nil {
+				return total, werr
+			}
+			total += len(rows)
+		}
+		if errors.Is(err, iss.EOF) {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// column колонка = поле структуры
+type column struct {
+	name     string
+	index    int
+	kind     reflect.Kind // тип значения (для ссылки = тип, на который она указывает)
+	optional bool         // поле-ссылка: nil = пустое значение
+}
+
+// value значение поля колонки в строке, false = пустое значение (nil)
+func (c column) value(row reflect.Value) (reflect.Value, bool) {
+	v := row.Field(c.index)
+	if !c.optional {
+		return v, true
+	}
+	if v.IsNil() {
+		return v, false
+	}
+	return v.Elem(), true
+}
+
+// schema колонки структуры
+type schema struct {
+	typ     reflect.Type
+	columns []column
+}
+
+// schemaOf колонки по типу структуры
+func schemaOf(t reflect.Type) (*schema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", ErrType, t)
+	}
+	s := &schema{typ: t}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // не экспортируемое поле
+		}
+		name := strings.Split(field.Tag.Get(iss.DefaultTagKey), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		typ, optional := field.Type, false
+		if typ.Kind() == reflect.Pointer {
+			typ, optional = typ.Elem(), true
+		}
+		switch kind := typ.Kind(); kind {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			s.columns = append(s.columns, column{name: name, index: i, kind: kind, optional: optional})
+		default:
+			return nil, fmt.Errorf("%w: поле %s.%s (%s)", ErrType, t.Name(), field.Name, field.Type)
+		}
+	}
+	return s, nil
+}
+
+// sliceOf проверим что rows = срез структур, вернем reflect.Value среза
+// при первом вызове определим схему, далее тип должен совпадать
+func sliceOf(rows interface{}, s **schema) (reflect.Value, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return v, fmt.Errorf("%w: ожидается срез структур, получено %T", ErrType, rows)
+	}
+	elem := v.Type().Elem()
+	if *s == nil {
+		schema, err := schemaOf(elem)
+		if err != nil {
+			return v, err
+		}
+		*s = schema
+	}
+	if elem != (*s).typ {
+		return v, fmt.Errorf("%w: ожидается []%s, получено %T", ErrType, (*s).typ, rows)
+	}
+	return v, nil
+}
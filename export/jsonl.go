@@ -0,0 +1,37 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONLWriter запись в формате JSON Lines: одна структура = одна строка json
+type JSONLWriter struct {
+	enc    *json.Encoder
+	schema *schema
+}
+
+// NewJSONLWriter запись JSON Lines в w
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{enc: json.NewEncoder(w)}
+}
+
+// Write записать срез структур
+func (j *JSONLWriter) Write(rows interface{}) error {
+	v, err := sliceOf(rows, &j.schema)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		// Encoder сам добавляет перевод строки
+		if err = j.enc.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close для JSON Lines буфера нет
+func (j *JSONLWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,364 @@
+package export
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+)
+
+// константы формата parquet (parquet.thrift)
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetFloat     = 4
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetUTF8     = 0 // ConvertedType
+	parquetUint8    = 11
+	parquetUint16   = 12
+	parquetUint32   = 13
+	parquetUint64   = 14
+	parquetInt8     = 15
+	parquetInt16    = 16
+	parquetRequired = 0 // FieldRepetitionType
+	parquetOptional = 1
+	parquetPlain    = 0 // Encoding
+	parquetRLE      = 3
+	parquetDataPage = 0 // PageType
+
+	parquetMagic = "PAR1"
+)
+
+// DefaultRowGroupSize строк в одной группе parquet по умолчанию
+const DefaultRowGroupSize = 100_000
+
+// ParquetWriter запись в формате Apache Parquet
+// поля-ссылки = OPTIONAL колонки (nil = null), остальные = REQUIRED; кодировка PLAIN, без сжатия
+// строки копятся в памяти до RowGroupSize, затем записываются группой
+// файл готов только после Close (метаданные пишутся в конце файла)
+type ParquetWriter struct {
+	RowGroupSize int
+
+	w         io.Writer
+	offset    int64 // записано байт
+	schema    *schema
+	columns   []parquetColumn
+	rows      int // строк в текущей группе
+	total     int64
+	rowGroups []parquetRowGroup
+	err       error
+}
+
+// parquetColumn буфер значений колонки текущей группы
+type parquetColumn struct {
+	data  []byte
+	bools []bool
+	defs  []bool // OPTIONAL колонка: значение задано (definition level = 1) или null
+}
+
+// parquetRowGroup метаданные записанной группы строк
+type parquetRowGroup struct {
+	rows   int64
+	size   int64
+	chunks []parquetChunk
+}
+
+// parquetChunk метаданные записанной колонки группы
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+// NewParquetWriter запись parquet в w
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{RowGroupSize: DefaultRowGroupSize, w: w}
+}
+
+// Write записать срез структур
+func (p *ParquetWriter) Write(rows interface{}) error {
+	if p.err != nil {
+		return p.err
+	}
+	v, err := sliceOf(rows, &p.schema)
+	if err != nil {
+		return err
+	}
+	if p.columns == nil {
+		p.columns = make([]parquetColumn, len(p.schema.columns))
+	}
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		for j, col := range p.schema.columns {
+			value, ok := col.value(row)
+			if col.optional {
+				p.columns[j].defs = append(p.columns[j].defs, ok)
+			}
+			if ok {
+				p.columns[j].append(value)
+			}
+		}
+		p.rows++
+		if p.RowGroupSize > 0 && p.rows >= p.RowGroupSize {
+			if err = p.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close записать последнюю группу и метаданные файла
+func (p *ParquetWriter) Close() error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.schema == nil {
+		return errors.New("parquet: нет данных (не известна схема)")
+	}
+	if err := p.flush(); err != nil {
+		return err
+	}
+	if err := p.writeMagic(); err != nil {
+		return err
+	}
+	meta := p.metadata()
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(meta)))
+	if err := p.write(meta); err != nil {
+		return err
+	}
+	if err := p.write(size[:]); err != nil {
+		return err
+	}
+	if err := p.write([]byte(parquetMagic)); err != nil {
+		return err
+	}
+	p.err = errors.New("parquet: файл закрыт")
+	return nil
+}
+
+// append значение поля в PLAIN кодировке
+func (c *parquetColumn) append(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		c.data = binary.LittleEndian.AppendUint32(c.data, uint32(v.Len()))
+		c.data = append(c.data, v.String()...)
+	case reflect.Bool:
+		c.bools = append(c.bools, v.Bool())
+	case reflect.Int32, reflect.Int16, reflect.Int8:
+		c.data = binary.LittleEndian.AppendUint32(c.data, uint32(v.Int()))
+	case reflect.Int, reflect.Int64:
+		c.data = binary.LittleEndian.AppendUint64(c.data, uint64(v.Int()))
+	case reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		c.data = binary.LittleEndian.AppendUint32(c.data, uint32(v.Uint()))
+	case reflect.Uint, reflect.Uint64:
+		c.data = binary.LittleEndian.AppendUint64(c.data, v.Uint())
+	case reflect.Float32:
+		c.data = binary.LittleEndian.AppendUint32(c.data, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		c.data = binary.LittleEndian.AppendUint64(c.data, math.Float64bits(v.Float()))
+	}
+}
+
+// page данные страницы: уровни определения (для OPTIONAL) и значения без null
+// для bool упакуем биты (младший бит = первое значение)
+func (c *parquetColumn) page(col column) []byte {
+	var data []byte
+	if col.optional {
+		data = c.definitionLevels()
+	}
+	if col.kind != reflect.Bool {
+		return append(data, c.data...)
+	}
+	return append(data, packBits(c.bools)...)
+}
+
+// definitionLevels уровни определения в кодировке RLE/bit-packed hybrid с длиной впереди:
+// один блок bit-packed с шириной 1 бит (заголовок = количество групп по 8 значений << 1 | 1)
+func (c *parquetColumn) definitionLevels() []byte {
+	levels := binary.AppendUvarint(nil, uint64((len(c.defs)+7)/8)<<1|1)
+	levels = append(levels, packBits(c.defs)...)
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	return append(data, levels...)
+}
+
+// packBits упакуем биты по 8 в байт (младший бит = первое значение)
+func packBits(values []bool) []byte {
+	data := make([]byte, (len(values)+7)/8)
+	for i, b := range values {
+		if b {
+			data[i/8] |= 1 << (i % 8)
+		}
+	}
+	return data
+}
+
+// parquetType физический тип колонки
+func parquetType(kind reflect.Kind) int32 {
+	switch kind {
+	case reflect.String:
+		return parquetByteArray
+	case reflect.Bool:
+		return parquetBoolean
+	case reflect.Int32, reflect.Int16, reflect.Int8, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return parquetInt32
+	case reflect.Float32:
+		return parquetFloat
+	case reflect.Float64:
+		return parquetDouble
+	}
+	return parquetInt64
+}
+
+// parquetConvertedType аннотация колонки: строки UTF8, беззнаковые и малые целые
+// без аннотации uint64 больше MaxInt64 читается отрицательным
+func parquetConvertedType(kind reflect.Kind) (int32, bool) {
+	switch kind {
+	case reflect.String:
+		return parquetUTF8, true
+	case reflect.Int8:
+		return parquetInt8, true
+	case reflect.Int16:
+		return parquetInt16, true
+	case reflect.Uint8:
+		return parquetUint8, true
+	case reflect.Uint16:
+		return parquetUint16, true
+	case reflect.Uint32:
+		return parquetUint32, true
+	case reflect.Uint, reflect.Uint64:
+		return parquetUint64, true
+	}
+	return 0, false
+}
+
+// flush записать накопленные строки одной группой: по одной странице на колонку
+func (p *ParquetWriter) flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+	if err := p.writeMagic(); err != nil {
+		return err
+	}
+	group := parquetRowGroup{rows: int64(p.rows)}
+	for i := range p.columns {
+		data := p.columns[i].page(p.schema.columns[i])
+
+		t := &thriftWriter{}
+		t.begin()
+		t.i32(1, parquetDataPage)
+		t.i32(2, int32(len(data))) // uncompressed_page_size
+		t.i32(3, int32(len(data))) // compressed_page_size
+		t.structField(5)           // data_page_header
+		t.i32(1, int32(p.rows))
+		t.i32(2, parquetPlain)
+		t.i32(3, parquetRLE)
+		t.i32(4, parquetRLE)
+		t.end()
+		t.end()
+
+		chunk := parquetChunk{offset: p.offset, size: int64(t.buf.Len() + len(data))}
+		if err := p.write(t.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+		c := &p.columns[i]
+		c.data, c.bools, c.defs = c.data[:0], c.bools[:0], c.defs[:0]
+	}
+	p.rowGroups = append(p.rowGroups, group)
+	p.total += int64(p.rows)
+	p.rows = 0
+	return nil
+}
+
+// metadata FileMetaData в thrift compact
+func (p *ParquetWriter) metadata() []byte {
+	columns := p.schema.columns
+	t := &thriftWriter{}
+	t.begin()
+	t.i32(1, 1) // version
+
+	// схема: корень + колонки
+	t.list(2, thriftStruct, len(columns)+1)
+	t.begin()
+	t.string(4, "schema")
+	t.i32(5, int32(len(columns)))
+	t.end()
+	for _, col := range columns {
+		t.begin()
+		t.i32(1, parquetType(col.kind))
+		if col.optional {
+			t.i32(3, parquetOptional)
+		} else {
+			t.i32(3, parquetRequired)
+		}
+		t.string(4, col.name)
+		if converted, ok := parquetConvertedType(col.kind); ok {
+			t.i32(6, converted)
+		}
+		t.end()
+	}
+
+	t.i64(3, p.total)
+
+	t.list(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		t.begin()
+		t.list(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			t.begin()
+			t.i64(2, chunk.offset) // file_offset
+			t.structField(3)       // meta_data
+			t.i32(1, parquetType(columns[i].kind))
+			if columns[i].optional {
+				t.list(2, thriftI32, 2) // encodings: значения PLAIN, уровни определения RLE
+				t.zigzag(parquetPlain)
+				t.zigzag(parquetRLE)
+			} else {
+				t.list(2, thriftI32, 1)
+				t.zigzag(parquetPlain)
+			}
+			t.list(3, thriftBinary, 1)
+			t.stringValue(columns[i].name)
+			t.i32(4, 0) // codec = UNCOMPRESSED
+			t.i64(5, group.rows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset) // data_page_offset
+			t.end()
+			t.end()
+		}
+		t.i64(2, group.size)
+		t.i64(3, group.rows)
+		t.end()
+	}
+
+	t.string(6, "go-moex-iss")
+	t.end()
+	return t.buf.Bytes()
+}
+
+// writeMagic заголовок файла (один раз)
+func (p *ParquetWriter) writeMagic() error {
+	if p.offset > 0 {
+		return nil
+	}
+	return p.write([]byte(parquetMagic))
+}
+
+func (p *ParquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	if err != nil {
+		p.err = err
+	}
+	return err
+}
@@ -0,0 +1,425 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// thriftReader чтение thrift compact protocol для проверки метаданных parquet
+// структура = map[id поля]значение, список = []interface{}, целые = int64, binary = string
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		panic("thrift: не верный varint")
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 3:
+		return int64(int8(r.byte()))
+	case 4, 5, 6:
+		return r.zigzag()
+	case 7:
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return v
+	case thriftBinary:
+		n := int(r.varint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		header := r.byte()
+		n, elem := int(header>>4), header&0x0F
+		if n == 15 {
+			n = int(r.varint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			if elem == 1 || elem == 2 {
+				// bool в списке = отдельный байт
+				list[i] = r.byte() == 1
+				continue
+			}
+			list[i] = r.value(elem)
+		}
+		return list
+	case thriftStruct:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("thrift: тип %d не поддерживается", typ))
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0F)
+		last = id
+	}
+}
+
+// parquetFile прочитанный файл: схема и значения колонок по всем группам (nil = null)
+type parquetFile struct {
+	meta      map[int16]interface{}
+	schema    []map[int16]interface{} // без корня
+	rowGroups int
+	columns   [][]interface{}
+}
+
+// readParquet разберем файл: footer, затем страницы каждой колонки каждой группы
+func readParquet(t *testing.T, data []byte) parquetFile {
+	t.Helper()
+	n := len(data)
+	if string(data[:4]) != parquetMagic || string(data[n-4:]) != parquetMagic {
+		t.Fatal("нет PAR1 в начале или в конце файла")
+	}
+	size := int(binary.LittleEndian.Uint32(data[n-8:]))
+	footer := &thriftReader{data: data[n-8-size : n-8]}
+	file := parquetFile{meta: footer.structure()}
+	if footer.pos != size {
+		t.Fatalf("footer: прочитано %d байт из %d", footer.pos, size)
+	}
+
+	for i, e := range file.meta[2].([]interface{}) {
+		if i > 0 {
+			file.schema = append(file.schema, e.(map[int16]interface{}))
+		}
+	}
+	file.columns = make([][]interface{}, len(file.schema))
+	groups := file.meta[4].([]interface{})
+	file.rowGroups = len(groups)
+	for _, g := range groups {
+		group := g.(map[int16]interface{})
+		rows := group[3].(int64)
+		for i, c := range group[1].([]interface{}) {
+			meta := c.(map[int16]interface{})[3].(map[int16]interface{})
+			if meta[5].(int64) != rows {
+				t.Fatalf("колонка %d: num_values %d, строк в группе %d", i, meta[5], rows)
+			}
+			offset := meta[9].(int64)
+			page := &thriftReader{data: data, pos: int(offset)}
+			header := page.structure()
+			body := data[page.pos : page.pos+int(header[3].(int64))]
+			if int64(page.pos-int(offset)+len(body)) != meta[7].(int64) {
+				t.Fatalf("колонка %d: total_compressed_size %d", i, meta[7])
+			}
+			values := readPage(t, file.schema[i], body, int(header[5].(map[int16]interface{})[1].(int64)))
+			file.columns[i] = append(file.columns[i], values...)
+		}
+	}
+	return file
+}
+
+// readPage значения страницы PLAIN (для OPTIONAL = с уровнями определения)
+func readPage(t *testing.T, schema map[int16]interface{}, body []byte, count int) []interface{} {
+	t.Helper()
+	defined := make([]bool, count)
+	for i := range defined {
+		defined[i] = true
+	}
+	if schema[3].(int64) == parquetOptional {
+		n := int(binary.LittleEndian.Uint32(body))
+		levels := &thriftReader{data: body[4 : 4+n]}
+		body = body[4+n:]
+		defined = defined[:0]
+		for levels.pos < n {
+			header := levels.varint()
+			if header&1 == 0 {
+				// RLE: повтор одного значения (ширина 1 бит = 1 байт)
+				value := levels.byte() == 1
+				for j := 0; j < int(header>>1); j++ {
+					defined = append(defined, value)
+				}
+				continue
+			}
+			for j := 0; j < int(header>>1)*8; j++ {
+				defined = append(defined, levels.data[levels.pos+j/8]&(1<<(j%8)) != 0)
+			}
+			levels.pos += int(header >> 1)
+		}
+		if len(defined) < count {
+			t.Fatalf("%s: уровней определения %d, значений %d", schema[4], len(defined), count)
+		}
+		defined = defined[:count]
+	}
+
+	values := make([]interface{}, count)
+	pos, bit := 0, 0
+	for i := range values {
+		if !defined[i] {
+			continue
+		}
+		switch schema[1].(int64) {
+		case parquetBoolean:
+			values[i] = body[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		case parquetInt32:
+			values[i] = int64(int32(binary.LittleEndian.Uint32(body[pos:])))
+			pos += 4
+		case parquetInt64:
+			values[i] = int64(binary.LittleEndian.Uint64(body[pos:]))
+			pos += 8
+		case parquetFloat:
+			values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(body[pos:])))
+			pos += 4
+		case parquetDouble:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(body[pos:]))
+			pos += 8
+		case parquetByteArray:
+			n := int(binary.LittleEndian.Uint32(body[pos:]))
+			values[i] = string(body[pos+4 : pos+4+n])
+			pos += 4 + n
+		}
+	}
+	if bit > 0 {
+		pos = (bit + 7) / 8
+	}
+	if pos != len(body) {
+		t.Fatalf("%s: прочитано %d байт страницы из %d", schema[4], pos, len(body))
+	}
+	return values
+}
+
+type parquetRow struct {
+	SecID    string   `json:"SECID"`
+	Traded   bool     `json:"IS_TRADED"`
+	Lot      int32    `json:"LOTSIZE"`
+	Volume   int64    `json:"VOLUME"`
+	Step     float32  `json:"MINSTEP"`
+	Close    float64  `json:"CLOSE"`
+	Last     *float64 `json:"LAST"`
+	Status   *string  `json:"STATUS"`
+	Primary  *bool    `json:"IS_PRIMARY"`
+	internal int
+	Skip     string `json:"-"`
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	str := func(s string) *string { return &s }
+	yes, no := true, false
+	rows := []parquetRow{
+		{"SBER", true, 10, 1_000_000, 0.01, 306.1, ptr(306.2), str("A"), &yes, 1, "x"},
+		{"GAZP", false, 10, -5, 0.01, 130.5, nil, nil, nil, 0, ""},
+		{"", true, 1, 0, 0.5, 0, ptr(0), str(""), &no, 0, ""},
+		{"Сбербанк ао", false, -1, math.MaxInt64, 1, -1.5, nil, str("N"), nil, 0, ""},
+		{"LKOH", true, 1, 1, 0.5, 7000, ptr(7001), nil, &yes, 0, ""},
+		{"YNDX", true, 1, 2, 0.2, 3500, nil, nil, nil, 0, ""},
+		{"ROSN", false, 1, 3, 0.05, 550, ptr(551), str("A"), &no, 0, ""},
+		{"VTBR", true, 10000, 4, 0.000005, 0.02, ptr(0.021), str("A"), &yes, 0, ""},
+		{"MOEX", false, 10, 5, 0.01, 200, nil, str("A"), nil, 0, ""},
+		{"TATN", true, 1, 6, 0.1, 650, ptr(651), nil, &no, 0, ""},
+	}
+
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf)
+	w.RowGroupSize = 4
+	// две записи: группы набираются через границу вызовов Write
+	if err := w.Write(rows[:3]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(rows[3:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(rows); err == nil {
+		t.Fatal("запись после Close без ошибки")
+	}
+
+	file := readParquet(t, buf.Bytes())
+	if file.meta[3].(int64) != int64(len(rows)) {
+		t.Fatalf("num_rows %d", file.meta[3])
+	}
+	if file.rowGroups != 3 {
+		t.Fatalf("групп %d, ожидали 3", file.rowGroups)
+	}
+
+	// схема: имя, физический тип, обязательность
+	wantSchema := []struct {
+		name string
+		typ  int64
+		rep  int64
+	}{
+		{"SECID", parquetByteArray, parquetRequired},
+		{"IS_TRADED", parquetBoolean, parquetRequired},
+		{"LOTSIZE", parquetInt32, parquetRequired},
+		{"VOLUME", parquetInt64, parquetRequired},
+		{"MINSTEP", parquetFloat, parquetRequired},
+		{"CLOSE", parquetDouble, parquetRequired},
+		{"LAST", parquetDouble, parquetOptional},
+		{"STATUS", parquetByteArray, parquetOptional},
+		{"IS_PRIMARY", parquetBoolean, parquetOptional},
+	}
+	if len(file.schema) != len(wantSchema) {
+		t.Fatalf("колонок %d, ожидали %d", len(file.schema), len(wantSchema))
+	}
+	for i, want := range wantSchema {
+		got := file.schema[i]
+		if got[4] != want.name || got[1] != want.typ || got[3] != want.rep {
+			t.Errorf("колонка %d: %v, ожидали %+v", i, got, want)
+		}
+		if converted, ok := got[6]; ok != (want.typ == parquetByteArray) || ok && converted != int64(parquetUTF8) {
+			t.Errorf("колонка %s: converted_type %v", want.name, got[6])
+		}
+	}
+
+	for i, row := range rows {
+		want := []interface{}{row.SecID, row.Traded, int64(row.Lot), row.Volume, float64(row.Step), row.Close, nil, nil, nil}
+		if row.Last != nil {
+			want[6] = *row.Last
+		}
+		if row.Status != nil {
+			want[7] = *row.Status
+		}
+		if row.Primary != nil {
+			want[8] = *row.Primary
+		}
+		for j := range want {
+			if got := file.columns[j][i]; !reflect.DeepEqual(got, want[j]) {
+				t.Errorf("строка %d, %s: %#v, ожидали %#v", i, wantSchema[j].name, got, want[j])
+			}
+		}
+	}
+}
+
+// беззнаковые и малые целые: физический тип INT32/INT64 с аннотацией converted_type
+func TestParquetIntegerTypes(t *testing.T) {
+	type row struct {
+		I8  int8   `json:"I8"`
+		I16 int16  `json:"I16"`
+		I32 int32  `json:"I32"`
+		U8  uint8  `json:"U8"`
+		U16 uint16 `json:"U16"`
+		U32 uint32 `json:"U32"`
+		U64 uint64 `json:"U64"`
+		U   uint   `json:"U"`
+	}
+	rows := []row{
+		{math.MinInt8, math.MinInt16, math.MinInt32, 0, 0, 0, 0, 0},
+		{math.MaxInt8, math.MaxInt16, math.MaxInt32, math.MaxUint8, math.MaxUint16, math.MaxUint32, math.MaxUint64, math.MaxInt64 + 1},
+	}
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf)
+	if err := w.Write(rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file := readParquet(t, buf.Bytes())
+
+	wantSchema := []struct {
+		name      string
+		typ       int64
+		converted int64 // -1 = без аннотации
+	}{
+		{"I8", parquetInt32, parquetInt8},
+		{"I16", parquetInt32, parquetInt16},
+		{"I32", parquetInt32, -1},
+		{"U8", parquetInt32, parquetUint8},
+		{"U16", parquetInt32, parquetUint16},
+		{"U32", parquetInt32, parquetUint32},
+		{"U64", parquetInt64, parquetUint64},
+		{"U", parquetInt64, parquetUint64},
+	}
+	if len(file.schema) != len(wantSchema) {
+		t.Fatalf("колонок %d, ожидали %d", len(file.schema), len(wantSchema))
+	}
+	for i, want := range wantSchema {
+		got := file.schema[i]
+		converted, ok := got[6]
+		if got[4] != want.name || got[1] != want.typ || ok != (want.converted >= 0) || ok && converted != want.converted {
+			t.Errorf("колонка %d: %v, ожидали %+v", i, got, want)
+		}
+	}
+
+	// значения хранятся битами: беззнаковое читается по аннотации
+	for i, r := range rows {
+		got := make([]int64, len(wantSchema))
+		for j := range got {
+			got[j] = file.columns[j][i].(int64)
+		}
+		if got[0] != int64(r.I8) || got[1] != int64(r.I16) || got[2] != int64(r.I32) {
+			t.Errorf("строка %d: знаковые %v", i, got[:3])
+		}
+		if uint8(got[3]) != r.U8 || uint16(got[4]) != r.U16 || uint32(got[5]) != r.U32 || uint64(got[6]) != r.U64 || uint(got[7]) != r.U {
+			t.Errorf("строка %d: беззнаковые %v", i, got[3:])
+		}
+	}
+}
+
+// уровни определения из нескольких групп по 8 и неполной группы
+func TestParquetDefinitionLevels(t *testing.T) {
+	var c parquetColumn
+	for i := 0; i < 19; i++ {
+		c.defs = append(c.defs, i%3 != 0)
+	}
+	data := c.definitionLevels()
+	if n := binary.LittleEndian.Uint32(data); int(n) != len(data)-4 {
+		t.Fatalf("длина %d, данных %d", n, len(data)-4)
+	}
+	// 3 группы по 8: заголовок (3 << 1) | 1
+	if data[4] != 7 || len(data) != 4+1+3 {
+		t.Fatalf("уровни %v", data)
+	}
+	for i, want := range c.defs {
+		if got := data[5+i/8]&(1<<(i%8)) != 0; got != want {
+			t.Fatalf("значение %d: %v", i, got)
+		}
+	}
+}
+
+func TestParquetErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewParquetWriter(&buf).Close(); err == nil {
+		t.Fatal("Close без данных без ошибки")
+	}
+	w := NewParquetWriter(&buf)
+	if err := w.Write([]parquetRow{{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]struct{ A int }{{}}); err == nil {
+		t.Fatal("другой тип строк без ошибки")
+	}
+	if err := NewParquetWriter(&buf).Write([]struct{ A []int }{{}}); err == nil {
+		t.Fatal("срез в поле без ошибки")
+	}
+}
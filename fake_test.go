@@ -0,0 +1,132 @@
+package iss
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// ошибки запросов библиотека пишет в slog по умолчанию
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// fakeHandler ответ на запрос: параметры строки запроса -> тело ответа
+type fakeHandler func(q url.Values) string
+
+// fakeISS http клиент для тестов без сети
+// ответ выбирается по пути запроса без /iss/ (например "securities/SBER.json"),
+// на неизвестный путь = 404 и пустой ответ
+type fakeISS struct {
+	mu       sync.Mutex
+	routes   map[string]fakeHandler
+	granted  bool     // отвечать с заголовком авторизации X-MicexPassport-Marker: granted
+	requests []string // все запросы: путь?параметры
+}
+
+func newFakeISS() *fakeISS {
+	return &fakeISS{routes: make(map[string]fakeHandler), granted: true}
+}
+
+// handle ответ на путь
+func (f *fakeISS) handle(path string, fn fakeHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[path] = fn
+}
+
+// count сколько было запросов по пути
+func (f *fakeISS) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.requests {
+		if strings.SplitN(r, "?", 2)[0] == path {
+			n++
+		}
+	}
+	return n
+}
+
+// last последний запрос по пути (путь?параметры)
+func (f *fakeISS) last(path string) (url.Values, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.requests) - 1; i >= 0; i-- {
+		parts := strings.SplitN(f.requests[i], "?", 2)
+		if parts[0] == path {
+			q, _ := url.ParseQuery(parts[len(parts)-1])
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+func (f *fakeISS) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/iss/")
+	f.mu.Lock()
+	f.requests = append(f.requests, path+"?"+req.URL.RawQuery)
+	fn, ok := f.routes[path]
+	granted := f.granted
+	f.mu.Unlock()
+
+	status, body := http.StatusOK, "{}"
+	if ok {
+		body = fn(req.URL.Query())
+	} else {
+		status = http.StatusNotFound
+	}
+	header := http.Header{}
+	if granted {
+		header.Set(autHeaderName, "granted")
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+// newFakeClient клиент, который ходит в fakeISS
+func newFakeClient(t *testing.T, f *fakeISS) *Client {
+	t.Helper()
+	c, err := NewClient(WithHTTPClient(f), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// table блок ответа iss
+func table(columns []string, rows ...[]interface{}) Table {
+	if rows == nil {
+		rows = [][]interface{}{}
+	}
+	return Table{Columns: columns, Data: rows}
+}
+
+// issJSON ответ iss в формате json из блоков
+func issJSON(blocks map[string]Table) string {
+	body, err := json.Marshal(blocks)
+	if err != nil {
+		panic(err)
+	}
+	return string(body)
+}
+
+// static один и тот же ответ на любой запрос
+func static(blocks map[string]Table) fakeHandler {
+	body := issJSON(blocks)
+	return func(url.Values) string { return body }
+}
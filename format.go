@@ -0,0 +1,512 @@
+package iss
+
+/*
+Разбор ответов ISS в форматах json, xml и csv в одинаковые блоки Table
+
+формат json (iss.json=extended):
+	первый элемент массива = служебная информация, дальше блоки в виде массива объектов
+
+	[
+		{"charsetinfo": {"name": "utf-8"}},
+		{
+			"securities": [
+				{"SECID": "SBER", "BOARDID": "TQBR", "SHORTNAME": "Сбербанк"}
+			]
+		}
+	]
+
+	с метаданными блок = объект: {"metadata": {"SECID": {"type": "string"}, ...}, "data": [...]}
+
+формат csv:
+	- кодировка cp1251
+	- разделитель ";"
+	- каждый блок начинается с названия блока и пустой строки,
+	  дальше строка заголовка и строки данных. Блоки разделены пустой строкой
+
+	securities
+
+	SECID;BOARDID;SHORTNAME
+	SBER;TQBR;Сбербанк
+
+	marketdata
+	...
+
+формат xml:
+	<document>
+		<data id="securities">
+			<metadata><columns><column name="SECID" .../></columns></metadata>
+			<rows><row SECID="SBER" BOARDID="TQBR" .../></rows>
+		</data>
+	</document>
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+)
+
+const (
+	FormatJson = "json"
+	FormatXml  = "xml"
+	FormatCsv  = "csv"
+)
+
+// Tables блоки ответа iss по названию блока
+type Tables map[string]Table
+
+// Decode разложим блоки по полям структуры v (по тегу json)
+// v = ссылка на структуру с полями типа Table (например Response)
+func (t Tables) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("must be a pointer to a struct")
+	}
+	sv := rv.Elem()
+	st := sv.Type()
+	for i := 0; i < sv.NumField(); i++ {
+		name := strings.Split(st.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = st.Field(i).Name
+		}
+		table, ok := t[name]
+		if !ok {
+			continue
+		}
+		field := sv.Field(i)
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+		columns := field.FieldByName("Columns")
+		data := field.FieldByName("Data")
+		if !columns.IsValid() || !data.IsValid() || !columns.CanSet() || !data.CanSet() {
+			continue
+		}
+		columns.Set(reflect.ValueOf(table.Columns))
+		data.Set(reflect.ValueOf(table.Data))
+	}
+	return nil
+}
+
+// formatFromURL определим формат ответа по расширению в url
+// по умолчанию json
+func formatFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return FormatJson
+	}
+	switch ext := strings.TrimPrefix(path.Ext(u.Path), "."); ext {
+	case FormatXml, FormatCsv:
+		return ext
+	}
+	return FormatJson
+}
+
+// decodeBody распарсим ответ сервера в заданном формате
+func decodeBody(format string, body []byte, v interface{}) error {
+	var tables Tables
+	var err error
+	switch format {
+	case FormatCsv:
+		tables, err = ParseCSV(bytes.NewReader(body))
+	case FormatXml:
+		tables, err = ParseXML(bytes.NewReader(body))
+	default:
+		if !isExtendedJSON(body) {
+			return json.Unmarshal(body, v)
+		}
+		tables, err = ParseExtendedJSON(bytes.NewReader(body))
+	}
+	if err != nil {
+		return err
+	}
+	return tables.Decode(v)
+}
+
+// isExtendedJSON ответ в формате iss.json=extended начинается с массива
+func isExtendedJSON(body []byte) bool {
+	body = bytes.TrimSpace(body)
+	return len(body) > 0 && body[0] == '['
+}
+
+// ParseExtendedJSON распарсим ответ iss в формате iss.json=extended
+// порядок колонок берем из порядка полей в первой строке блока
+func ParseExtendedJSON(r io.Reader) (Tables, error) {
+	tables := make(Tables)
+	decoder := json.NewDecoder(r)
+	if err := expectDelim(decoder, '['); err != nil {
+		return nil, err
+	}
+	for decoder.More() {
+		// каждый элемент = объект с блоками
+		if err := expectDelim(decoder, '{'); err != nil {
+			return nil, err
+		}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			name, ok := token.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected token %v", token)
+			}
+			var raw json.RawMessage
+			if err = decoder.Decode(&raw); err != nil {
+				return nil, err
+			}
+			var table Table
+			if isExtendedJSON(raw) {
+				table, err = parseExtendedBlock(raw, nil)
+			} else {
+				// блок с метаданными (iss.meta=on) или служебный блок (charsetinfo)
+				table, ok, err = parseExtendedMeta(raw)
+				if err == nil && !ok {
+					continue
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("block %s: %w", name, err)
+			}
+			tables[name] = table
+		}
+		if err := expectDelim(decoder, '}'); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// parseExtendedMeta распарсим блок в виде объекта
+// {"metadata": {"SECID": {...}, ...}, "data": [{...}, ...]}
+// порядок колонок берем из metadata (колонки есть даже у пустого блока)
+// false = в объекте нет data (служебный блок)
+func parseExtendedMeta(raw []byte) (Table, bool, error) {
+	var columns []string
+	var data json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return Table{}, false, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return Table{}, false, err
+		}
+		switch token {
+		case "metadata":
+			if columns, err = objectKeys(decoder); err != nil {
+				return Table{}, false, err
+			}
+		case "data":
+			if err = decoder.Decode(&data); err != nil {
+				return Table{}, false, err
+			}
+		default:
+			var skip json.RawMessage
+			if err = decoder.Decode(&skip); err != nil {
+				return Table{}, false, err
+			}
+		}
+	}
+	if !isExtendedJSON(data) {
+		return Table{}, false, nil
+	}
+	table, err := parseExtendedBlock(data, columns)
+	return table, true, err
+}
+
+// objectKeys названия полей объекта в порядке следования
+func objectKeys(decoder *json.Decoder) ([]string, error) {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v", token)
+		}
+		var skip json.RawMessage
+		if err = decoder.Decode(&skip); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, expectDelim(decoder, '}')
+}
+
+// parseExtendedBlock распарсим один блок: массив объектов
+// columns = известный заранее порядок колонок (может быть nil)
+func parseExtendedBlock(raw []byte, columns []string) (Table, error) {
+	table := Table{Data: make([][]interface{}, 0)}
+	position := make(map[string]int) // номер колонки по названию
+	rows := make([]map[string]interface{}, 0)
+	for _, column := range columns {
+		if _, ok := position[column]; !ok {
+			position[column] = len(table.Columns)
+			table.Columns = append(table.Columns, column)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if err := expectDelim(decoder, '['); err != nil {
+		return table, err
+	}
+	for decoder.More() {
+		if err := expectDelim(decoder, '{'); err != nil {
+			return table, err
+		}
+		row := make(map[string]interface{})
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return table, err
+			}
+			column, ok := token.(string)
+			if !ok {
+				return table, fmt.Errorf("unexpected token %v", token)
+			}
+			var value interface{}
+			if err = decoder.Decode(&value); err != nil {
+				return table, err
+			}
+			if _, ok := position[column]; !ok {
+				position[column] = len(table.Columns)
+				table.Columns = append(table.Columns, column)
+			}
+			row[column] = value
+		}
+		if err := expectDelim(decoder, '}'); err != nil {
+			return table, err
+		}
+		rows = append(rows, row)
+	}
+	for _, row := range rows {
+		data := make([]interface{}, len(table.Columns))
+		for column, value := range row {
+			data[position[column]] = value
+		}
+		table.Data = append(table.Data, data)
+	}
+	return table, nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// ParseCSV распарсим ответ iss в формате csv
+// iss всегда отдает csv в cp1251, поэтому кодировку не угадываем
+// (короткий текст в cp1251 может оказаться корректным utf-8)
+func ParseCSV(r io.Reader) (Tables, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := decodeCP1251(body)
+
+	tables := make(Tables)
+	var name string    // текущий блок
+	var lines []string // строки текущего блока (заголовок + данные)
+	flush := func() error {
+		if name == "" {
+			return nil
+		}
+		table, err := parseCSVBlock(lines)
+		if err != nil {
+			return fmt.Errorf("block %s: %w", name, err)
+		}
+		tables[name] = table
+		name = ""
+		lines = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			// пустая строка после названия блока = пропускаем
+			// пустая строка после данных = конец блока
+			if len(lines) > 0 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+		case name == "":
+			name = strings.TrimSpace(line)
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// parseCSVBlock распарсим один блок: заголовок + строки данных
+func parseCSVBlock(lines []string) (Table, error) {
+	table := Table{Data: make([][]interface{}, 0)}
+	if len(lines) == 0 {
+		return table, nil
+	}
+	reader := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	reader.Comma = ';'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return table, err
+	}
+	if len(records) == 0 {
+		return table, nil
+	}
+	table.Columns = records[0]
+	for _, record := range records[1:] {
+		row := make([]interface{}, len(table.Columns))
+		for i := range row {
+			if i < len(record) {
+				row[i] = record[i]
+			}
+		}
+		table.Data = append(table.Data, row)
+	}
+	return table, nil
+}
+
+// ParseXML распарсим ответ iss в формате xml
+func ParseXML(r io.Reader) (Tables, error) {
+	tables := make(Tables)
+	decoder := xml.NewDecoder(r)
+	// iss отдает xml в utf-8, но на всякий случай поддержим и cp1251
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "windows-1251", "cp1251":
+			body, err := io.ReadAll(input)
+			if err != nil {
+				return nil, err
+			}
+			return strings.NewReader(decodeCP1251(body)), nil
+		}
+		return input, nil
+	}
+
+	var name string              // текущий блок
+	var table Table              // данные текущего блока
+	var rows []map[string]string // строки текущего блока
+	var position map[string]int  // номер колонки по названию
+	addColumn := func(column string) {
+		if _, ok := position[column]; ok {
+			return
+		}
+		position[column] = len(table.Columns)
+		table.Columns = append(table.Columns, column)
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "data":
+				name = xmlAttr(el, "id")
+				table = Table{Data: make([][]interface{}, 0)}
+				rows = nil
+				position = make(map[string]int)
+			case "column":
+				if name != "" {
+					addColumn(xmlAttr(el, "name"))
+				}
+			case "row":
+				if name == "" {
+					continue
+				}
+				row := make(map[string]string, len(el.Attr))
+				for _, attr := range el.Attr {
+					addColumn(attr.Name.Local)
+					row[attr.Name.Local] = attr.Value
+				}
+				rows = append(rows, row)
+			}
+		case xml.EndElement:
+			if el.Name.Local == "data" && name != "" {
+				for _, row := range rows {
+					data := make([]interface{}, len(table.Columns))
+					for column, value := range row {
+						data[position[column]] = value
+					}
+					table.Data = append(table.Data, data)
+				}
+				tables[name] = table
+				name = ""
+			}
+		}
+	}
+	return tables, nil
+}
+
+func xmlAttr(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// cp1251 символы 0x80-0xBF (0xC0-0xFF = А-я подряд)
+var cp1251 = [64]rune{
+	'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+	'ђ', '‘', '’', '“', '”', '•', '–', '—', '\ufffd', '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+	'\u00a0', 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', '\u00ad', '®', 'Ї',
+	'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+}
+
+// decodeCP1251 перекодируем cp1251 в utf-8
+func decodeCP1251(in []byte) string {
+	sb := strings.Builder{}
+	sb.Grow(len(in) * 2)
+	for _, b := range in {
+		switch {
+		case b < 0x80:
+			sb.WriteByte(b)
+		case b < 0xC0:
+			sb.WriteRune(cp1251[b-0x80])
+		default:
+			sb.WriteRune(rune(b-0xC0) + 'А')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package iss
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseExtendedJSON(t *testing.T) {
+	body := readFixture(t, "history_extended.json")
+	tables, err := ParseExtendedJSON(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tables["charsetinfo"]; ok {
+		t.Fatal("служебный блок charsetinfo попал в таблицы")
+	}
+
+	history := tables["history"]
+	// порядок колонок = порядок metadata
+	wantColumns := []string{"BOARDID", "TRADEDATE", "SECID", "CLOSE", "VOLUME", "WAPRICE"}
+	if !reflect.DeepEqual(history.Columns, wantColumns) {
+		t.Fatalf("columns = %v", history.Columns)
+	}
+	if len(history.Data) != 2 || history.Data[1][5] != nil {
+		t.Fatalf("data = %v", history.Data)
+	}
+
+	// пустой блок с метаданными = колонки есть, данных нет
+	marketdata := tables["marketdata"]
+	if !reflect.DeepEqual(marketdata.Columns, []string{"SECID", "LAST"}) || len(marketdata.Data) != 0 {
+		t.Fatalf("marketdata = %+v", marketdata)
+	}
+
+	var resp Response
+	if err = decodeBody(FormatJson, body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	cursor, ok := parseCursor(resp.HistoryCursor.Columns, resp.HistoryCursor.Data)
+	if !ok || cursor != (Cursor{Index: 0, Total: 243, PageSize: 100}) {
+		t.Fatalf("cursor = %+v ok=%v", cursor, ok)
+	}
+	type row struct {
+		TradeDate string  `json:"TRADEDATE"`
+		Close     float64 `json:"CLOSE"`
+		Volume    int64   `json:"VOLUME"`
+		WaPrice   float64 `json:"WAPRICE"`
+	}
+	var rows []row
+	if err = Unmarshal(resp.History.Columns, resp.History.Data, &rows); err != nil {
+		t.Fatal(err)
+	}
+	want := []row{
+		{TradeDate: "2024-08-01", Close: 282.22, Volume: 45210330, WaPrice: 283.41},
+		{TradeDate: "2024-08-02", Close: 277.25, Volume: 48011380},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows = %+v", rows)
+	}
+}
+
+func TestParseExtendedJSONWithoutMetadata(t *testing.T) {
+	body := []byte(`[{"charsetinfo": {"name": "utf-8"}}, {"securities": [{"SECID": "SBER", "LOTSIZE": 10}, {"SECID": "GAZP", "ISIN": "RU0007661625"}]}]`)
+	tables, err := ParseExtendedJSON(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	securities := tables["securities"]
+	if !reflect.DeepEqual(securities.Columns, []string{"SECID", "LOTSIZE", "ISIN"}) {
+		t.Fatalf("columns = %v", securities.Columns)
+	}
+	want := [][]interface{}{{"SBER", 10.0, nil}, {"GAZP", nil, "RU0007661625"}}
+	if !reflect.DeepEqual(securities.Data, want) {
+		t.Fatalf("data = %v", securities.Data)
+	}
+}
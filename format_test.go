@@ -0,0 +1,100 @@
+package iss
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// fixtureSecurity строка блока securities из fixture
+type fixtureSecurity struct {
+	SecID     string  `json:"SECID"`
+	BoardID   string  `json:"BOARDID"`
+	ShortName string  `json:"SHORTNAME"`
+	LotSize   int     `json:"LOTSIZE"`
+	PrevPrice float64 `json:"PREVPRICE"`
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestParseCSV(t *testing.T) {
+	body := readFixture(t, "securities.csv")
+	var resp Response
+	if err := decodeBody(FormatCsv, body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	var list []fixtureSecurity
+	if err := Unmarshal(resp.Securities.Columns, resp.Securities.Data, &list); err != nil {
+		t.Fatal(err)
+	}
+	want := []fixtureSecurity{
+		{SecID: "SBER", BoardID: "TQBR", ShortName: "Сбербанк", LotSize: 10, PrevPrice: 305.5},
+		{SecID: "GAZP", BoardID: "TQBR", ShortName: "ГАЗПРОМ ао", LotSize: 10, PrevPrice: 128.12},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Fatalf("securities = %+v", list)
+	}
+	if !reflect.DeepEqual(resp.MarketData.Columns, []string{"SECID", "LAST"}) || len(resp.MarketData.Data) != 1 {
+		t.Fatalf("marketdata = %+v", resp.MarketData)
+	}
+}
+
+func TestParseCSVShortCP1251(t *testing.T) {
+	// "Ц№1" в cp1251 = D6 B9 31: одновременно корректный utf-8 ("ֹ1")
+	body := []byte("securities\r\n\r\nSECID;SHORTNAME\r\nX;\xd6\xb91\r\n")
+	tables, err := ParseCSV(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := tables["securities"].Data[0][1]
+	if got != "Ц№1" {
+		t.Fatalf("SHORTNAME = %q", got)
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	body := readFixture(t, "securities.xml")
+	var resp Response
+	if err := decodeBody(FormatXml, body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp.Securities.Columns, []string{"SECID", "BOARDID", "SHORTNAME", "LOTSIZE"}) {
+		t.Fatalf("columns = %v", resp.Securities.Columns)
+	}
+	var list []fixtureSecurity
+	if err := Unmarshal(resp.Securities.Columns, resp.Securities.Data, &list); err != nil {
+		t.Fatal(err)
+	}
+	want := []fixtureSecurity{
+		{SecID: "SBER", BoardID: "TQBR", ShortName: "Сбербанк", LotSize: 10},
+		{SecID: "GAZP", BoardID: "TQBR", ShortName: "ГАЗПРОМ ао", LotSize: 10},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Fatalf("securities = %+v", list)
+	}
+	if len(resp.MarketData.Data) != 1 || resp.MarketData.Data[0][1] != "306.1" {
+		t.Fatalf("marketdata = %+v", resp.MarketData)
+	}
+}
+
+func TestFormatFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://iss.moex.com/iss/securities.json?q=SBER": FormatJson,
+		"https://iss.moex.com/iss/securities.xml":         FormatXml,
+		"https://iss.moex.com/iss/securities.csv?start=1": FormatCsv,
+		"https://iss.moex.com/iss/securities":             FormatJson,
+	}
+	for rawURL, want := range tests {
+		if got := formatFromURL(rawURL); got != want {
+			t.Errorf("%s: %s, ожидали %s", rawURL, got, want)
+		}
+	}
+}
@@ -90,9 +90,9 @@ func (c *Client) GetFortsInfo(symbols string) ([]FortsInfo, error) {
 		fullURL: url,
 	}
 	var resp Response
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error("GetFortsInfo.getJSON", "err", err.Error())
+		slog.Error("GetFortsInfo.getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -111,15 +111,15 @@ func (c *Client) GetFortsData(symbols string) ([]FortsData, error) {
 	var err error
 	const op = "GetFortsData"
 
-	url := NewIssRequest().Forts().Json().MetaData(false).OnlyMarketData().Symbols(symbols).URL()
+	url := NewIssRequest().Forts().Json().MetaData(false).OnlyMarketData().ColumnsOf("marketdata", FortsData{}).Symbols(symbols).URL()
 	r := &request{
 		method:  http.MethodGet,
 		fullURL: url,
 	}
 	var resp Response
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error("GetFortsData.getJSON", "err", err.Error())
+		slog.Error("GetFortsData.getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -32,6 +32,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 // FutOI Открытые позиции по фьючерсным контрактам в разрезе физ. и юр. лиц
@@ -52,7 +53,7 @@ type FutOI struct {
 
 // GetFutOIAll Открытые позиции физ. и юр. лиц по всем инструментам
 // date = за дату ; latest =1 возвращает последнюю пятиминутку за указанную дату
-func (c *Client) GetFutOIAll(date string, latest int) ([]FutOI, error) {
+func (c *Client) GetFutOIAll(date time.Time, latest int) ([]FutOI, error) {
 	var err error
 	const op = "GetFutOIAll"
 	url := "https://iss.moex.com/iss/analyticalproducts/futoi/securities.json"
@@ -60,8 +61,8 @@ func (c *Client) GetFutOIAll(date string, latest int) ([]FutOI, error) {
 		method:  http.MethodGet,
 		baseURL: url,
 	}
-	if date != "" {
-		r.setParam("date", date)
+	if !date.IsZero() {
+		r.setParam("date", date.In(TzMsk).Format(dateLayout))
 	}
 	if latest == 1 {
 		r.setParam("latest", latest)
@@ -74,9 +75,9 @@ func (c *Client) GetFutOIAll(date string, latest int) ([]FutOI, error) {
 		} `json:"futoi"`
 	}
 	var resp requestData
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -91,19 +92,22 @@ func (c *Client) GetFutOIAll(date string, latest int) ([]FutOI, error) {
 }
 
 // GetFutOI по заданному тикеру
-func (c *Client) GetFutOI(ticker string, from, to string, latest int) ([]FutOI, error) {
+func (c *Client) GetFutOI(ticker string, from, to time.Time, latest int) ([]FutOI, error) {
 	var err error
 	const op = "GetFutOI"
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, fmt.Errorf("%s: %w", op, ErrDateRange)
+	}
 	url := "https://iss.moex.com/iss/analyticalproducts/futoi/securities/" + ticker + ".json"
 	r := &request{
 		method:  http.MethodGet,
 		baseURL: url,
 	}
-	if from != "" {
-		r.setParam("from", from)
+	if !from.IsZero() {
+		r.setParam("from", from.In(TzMsk).Format(dateLayout))
 	}
-	if to != "" {
-		r.setParam("till", to)
+	if !to.IsZero() {
+		r.setParam("till", to.In(TzMsk).Format(dateLayout))
 	}
 	if latest == 1 {
 		r.setParam("latest", latest)
@@ -116,9 +120,9 @@ func (c *Client) GetFutOI(ticker string, from, to string, latest int) ([]FutOI,
 		} `json:"futoi"`
 	}
 	var resp requestData
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
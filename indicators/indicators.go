@@ -0,0 +1,152 @@
+/*
+Package indicators технические индикаторы по свечам iss.Candles и супер свечам iss.TradeStats
+
+Каждый индикатор есть в двух вариантах:
+потоковый = NewSMA(20).Update(close) по одному значению (например для свечей в реальном времени),
+пакетный = CalcSMA(closes, 20) по всему ряду.
+
+Период разогрева: пока данных не хватает, Update возвращает false,
+а пакетные функции ставят math.NaN() (длина результата всегда равна длине входа).
+
+Расчеты совпадают с классическими формулами (TA-Lib):
+EMA и MACD начинаются с SMA первых значений, RSI и ATR сглаживаются по Уайлдеру,
+Bollinger использует стандартное отклонение генеральной совокупности.
+Отличия от TA-Lib: при нулевом диапазоне цен RSI = 50 и стохастик %K = 50 (в TA-Lib = 0),
+OBV начинается с 0 (в TA-Lib с объема первого бара).
+
+Период <= 0: конструкторы New* возвращают ошибку ErrPeriod (как iss.NewBarBuilder),
+пакетные Calc* возвращают ряд из math.NaN() той же длины (разогрев не заканчивается).
+*/
+package indicators
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+// ErrPeriod период индикатора должен быть больше 0
+var ErrPeriod = errors.New("период индикатора должен быть больше 0")
+
+// checkPeriod проверим периоды индикатора (ErrPeriod, если period <= 0)
+func checkPeriod(name string, periods ...int) error {
+	for _, period := range periods {
+		if period <= 0 {
+			return fmt.Errorf("indicators.%s: %w: %d", name, ErrPeriod, period)
+		}
+	}
+	return nil
+}
+
+// Bar входные данные для индикаторов по барам (ATR, VWAP, OBV, Stochastic)
+type Bar struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+	Value  float64
+}
+
+// FromCandles бары из свечей
+func FromCandles(candles iss.Candles) []Bar {
+	bars := make([]Bar, 0, len(candles.Data))
+	for _, k := range candles.Data {
+		bars = append(bars, Bar{
+			Time:   k.Time(),
+			Open:   k.Open,
+			High:   k.High,
+			Low:    k.Low,
+			Close:  k.Close,
+			Volume: k.Volume,
+			Value:  k.Value,
+		})
+	}
+	return bars
+}
+
+// FromTradeStats бары из супер свечей (время = tradedate + tradetime по московскому времени)
+func FromTradeStats(stats []iss.TradeStats) []Bar {
+	bars := make([]Bar, 0, len(stats))
+	for _, s := range stats {
+		t, _ := iss.ParseDate(s.TradeDate + " " + s.TradeTime)
+		bars = append(bars, Bar{
+			Time:   t,
+			Open:   s.Open,
+			High:   s.High,
+			Low:    s.Low,
+			Close:  s.Close,
+			Volume: float64(s.Volume),
+			Value:  s.Value,
+		})
+	}
+	return bars
+}
+
+// Closes цены закрытия баров
+func Closes(bars []Bar) []float64 {
+	result := make([]float64, len(bars))
+	for i, bar := range bars {
+		result[i] = bar.Close
+	}
+	return result
+}
+
+// series пакетный расчет через потоковый индикатор, до окончания разогрева = empty
+func series[T, R any](in []T, update func(T) (R, bool), empty R) []R {
+	result := make([]R, len(in))
+	for i, v := range in {
+		r, ok := update(v)
+		if !ok {
+			r = empty
+		}
+		result[i] = r
+	}
+	return result
+}
+
+// fill ряд из n значений v (пакетный расчет с не верным периодом)
+func fill[R any](n int, v R) []R {
+	result := make([]R, n)
+	for i := range result {
+		result[i] = v
+	}
+	return result
+}
+
+// window последние n значений
+type window struct {
+	values []float64
+	pos    int
+	full   bool
+}
+
+func newWindow(n int) *window {
+	return &window{values: make([]float64, n)}
+}
+
+// push добавим значение, вернем вытесненное (если окно было заполнено)
+func (w *window) push(v float64) (float64, bool) {
+	old, full := w.values[w.pos], w.full
+	w.values[w.pos] = v
+	w.pos++
+	if w.pos == len(w.values) {
+		w.pos = 0
+		w.full = true
+	}
+	return old, full
+}
+
+// each значения окна от старого к новому
+func (w *window) each(fn func(i int, v float64)) {
+	n := len(w.values)
+	for i := 0; i < n; i++ {
+		fn(i, w.values[(w.pos+i)%n])
+	}
+}
+
+var nan = math.NaN()
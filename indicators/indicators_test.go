@@ -0,0 +1,246 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+/*
+Эталонные значения
+RSI(14) по ценам закрытия = таблица StockCharts (ChartSchool, Relative Strength Index): 70.46, 66.25, 66.48 ...
+SMA, EMA, WMA, MACD, BBANDS, ATR, STOCHF = по алгоритмам TA-Lib (ta_SMA.c, ta_EMA.c, ta_MACD.c ...)
+VWAP и OBV = по определению (OBV от 0)
+*/
+
+// closes цены закрытия из примера RSI StockCharts
+var closes = []float64{44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64, 46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57, 43.42, 42.66, 43.13}
+
+// testBars часовые бары за два дня: O H L C V
+var testBars = func() []Bar {
+	rows := []struct {
+		time            string
+		o, h, l, c, vol float64
+	}{
+		{"2024-08-05 10:00:00", 100.0, 101.5, 99.5, 101.0, 120},
+		{"2024-08-05 11:00:00", 101.0, 102.0, 100.2, 101.8, 90},
+		{"2024-08-05 12:00:00", 101.8, 103.1, 101.1, 102.9, 150},
+		{"2024-08-05 13:00:00", 102.9, 103.0, 101.6, 101.9, 80},
+		{"2024-08-05 14:00:00", 101.9, 102.4, 100.8, 101.0, 110},
+		{"2024-08-05 15:00:00", 101.0, 101.3, 99.9, 100.4, 130},
+		{"2024-08-05 16:00:00", 100.4, 101.9, 100.1, 101.7, 70},
+		{"2024-08-05 17:00:00", 101.7, 102.6, 101.2, 102.5, 95},
+		{"2024-08-05 18:00:00", 102.5, 102.5, 102.5, 102.5, 0},
+		{"2024-08-06 10:00:00", 103.0, 104.2, 102.7, 103.9, 160},
+		{"2024-08-06 11:00:00", 103.9, 104.0, 102.9, 103.1, 140},
+		{"2024-08-06 12:00:00", 103.1, 103.6, 102.2, 102.4, 100},
+		{"2024-08-06 13:00:00", 102.4, 102.9, 101.5, 101.8, 125},
+		{"2024-08-06 14:00:00", 101.8, 102.7, 101.6, 102.6, 85},
+		{"2024-08-06 15:00:00", 102.6, 103.8, 102.4, 103.5, 105},
+		{"2024-08-06 16:00:00", 103.5, 104.6, 103.3, 104.4, 115},
+	}
+	bars := make([]Bar, 0, len(rows))
+	for _, r := range rows {
+		t, _ := iss.ParseDate(r.time)
+		bars = append(bars, Bar{Time: t, Open: r.o, High: r.h, Low: r.l, Close: r.c, Volume: r.vol})
+	}
+	return bars
+}()
+
+var want = struct {
+	sma5, ema5, wma5, rsi14         []float64
+	macd, signal, hist              []float64
+	bbMiddle, bbUpper, bbLower      []float64
+	atr5, stochK, stochD, vwap, obv []float64
+}{
+	sma5:     []float64{nan, nan, nan, nan, 44.104, 44.202, 44.404, 44.658, 45.104, 45.454, 45.666, 45.852, 45.89, 45.978, 46.018, 46.04, 46.04, 46.2, 46.188, 46.06, 46.102, 46.146, 46.006, 46.052, 46.08, 45.908, 45.464, 45.158, 44.712, 44.47, 44.084, 43.81, 43.6},
+	ema5:     []float64{nan, nan, nan, nan, 44.104, 44.346, 44.5973333333, 44.8715555556, 45.1943703704, 45.4895802469, 45.6230534979, 45.758702332, 45.709134888, 45.8994232586, 46.0262821724, 46.0175214483, 46.0216809655, 46.1511206437, 46.1740804291, 45.9960536194, 46.0673690796, 46.1282460531, 45.988830702, 46.1425538014, 46.0217025342, 45.7978016895, 45.2085344597, 44.8656896398, 44.6504597599, 44.6236398399, 44.2224265599, 43.7016177066, 43.5110784711},
+	wma5:     []float64{nan, nan, nan, nan, 44.0706666667, 44.3126666667, 44.612, 44.9506666667, 45.3446666667, 45.67, 45.8153333333, 45.9366666667, 45.856, 45.986, 46.0866666667, 46.0806666667, 46.0773333333, 46.2006666667, 46.2073333333, 46.0246666667, 46.0746666667, 46.124, 45.9786666667, 46.1266666667, 46.036, 45.7926666667, 45.1666666667, 44.7386666667, 44.426, 44.3786666667, 44.0286666667, 43.554, 43.3273333333},
+	rsi14:    []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, 70.4641350211, 66.2496185536, 66.4809418347, 69.3468531629, 66.2947126589, 57.9150206701, 62.88071831, 63.2087887183, 56.0115847895, 62.3399293109, 54.6709713777, 50.3868151951, 40.0194237913, 41.4926354042, 41.9024296785, 45.4994972387, 37.3227783134, 33.0904825727, 37.7887719821},
+	macd:     []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, 0.4471793695, 0.453966061, 0.4301547981, 0.3486726064, 0.2876415559, 0.2944948281, 0.2515969263, 0.1250709844, 0.1348404109, 0.138057649, 0.0496271941, 0.1105059038, 0.0354999938, -0.0727153507, -0.3273349113, -0.4236525558, -0.4444529342, -0.3758317507, -0.4898667566, -0.6375302898, -0.6082237145},
+	signal:   []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, 0.5779185797, 0.5283375722, 0.4890644626, 0.4329077201, 0.3748012544, 0.3426786839, 0.3062459809, 0.2337759823, 0.1942017537, 0.1717441118, 0.1228973447, 0.1179407684, 0.0849644585, 0.0218925348, -0.1177984436, -0.2401400885, -0.3218652268, -0.3434518364, -0.4020178045, -0.4962227986, -0.541023165},
+	hist:     []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, -0.1307392103, -0.0743715113, -0.0589096645, -0.0842351137, -0.0871596985, -0.0481838558, -0.0546490545, -0.1087049979, -0.0593613428, -0.0336864628, -0.0732701507, -0.0074348645, -0.0494644647, -0.0946078855, -0.2095364677, -0.1835124673, -0.1225877075, -0.0323799144, -0.0878489522, -0.1413074912, -0.0672005496},
+	bbMiddle: []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, 44.779, 44.934, 45.128, 45.274, 45.541, 45.736, 45.853, 45.946, 46.045, 46.083, 46.039, 46.071, 46.093, 46.103, 46.12, 46.07, 46.005, 45.805, 45.582, 45.382, 45.275, 44.996, 44.637, 44.379},
+	bbUpper:  []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, 46.3238093734, 46.5792890324, 46.7869104858, 46.8157704109, 46.7196246222, 46.6681459113, 46.5697175176, 46.4606027594, 46.4933971454, 46.5197195897, 46.5503472401, 46.581094109, 46.6130038461, 46.5881432778, 46.6394612594, 46.6139117575, 46.7009454002, 47.1777126429, 47.192982309, 47.1182211841, 47.0654692122, 46.9761858499, 46.8639629543, 46.6463764575},
+	bbLower:  []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, 43.2341906266, 43.2887109676, 43.4690895142, 43.7322295891, 44.3623753778, 44.8038540887, 45.1362824824, 45.4313972406, 45.5966028546, 45.6462804103, 45.5276527599, 45.560905891, 45.5729961539, 45.6178567222, 45.6005387406, 45.5260882425, 45.3090545998, 44.4322873571, 43.971017691, 43.6457788159, 43.4845307878, 43.0158141501, 42.4100370457, 42.1116235425},
+	atr5:     []float64{nan, nan, nan, nan, nan, 1.64, 1.672, 1.6176, 1.29408, 1.375264, 1.3202112, 1.33616896, 1.348935168, 1.2991481344, 1.3193185075, 1.315454806},
+	stochK:   []float64{nan, nan, nan, nan, nan, nan, 56.25, 83.8709677419, 96.2962962963, 93.023255814, 73.1707317073, 40.0, 11.1111111111, 40.7407407407, 80.0, 93.5483870968},
+	stochD:   []float64{nan, nan, nan, nan, nan, nan, 37.8472222222, 51.9153225806, 78.8057546794, 91.0635066174, 87.4967612725, 68.7313291738, 41.4272809395, 30.6172839506, 43.950617284, 71.4297092792},
+	vwap:     []float64{100.6666666667, 100.9523809524, 101.5416666667, 101.6553030303, 101.6042424242, 101.3995098039, 101.384, 101.4644970414, 101.4644970414, 103.6, 103.4755555556, 103.29, 102.9987301587, 102.9013661202, 102.9501165501, 103.109437751},
+	obv:      []float64{0.0, 90.0, 240.0, 160.0, 50.0, -80.0, -10.0, 85.0, 85.0, 245.0, 105.0, 5.0, -120.0, -35.0, 70.0, 185.0},
+}
+
+// equalSeries сравним ряды с точностью 1e-8 (NaN = период разогрева)
+func equalSeries(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: длина %d, ожидали %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || math.Abs(got[i]-want[i]) > 1e-8 {
+			t.Errorf("%s[%d] = %v, ожидали %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func TestMovingAverages(t *testing.T) {
+	equalSeries(t, "SMA(5)", CalcSMA(closes, 5), want.sma5)
+	equalSeries(t, "EMA(5)", CalcEMA(closes, 5), want.ema5)
+	equalSeries(t, "WMA(5)", CalcWMA(closes, 5), want.wma5)
+}
+
+func TestRSI(t *testing.T) {
+	equalSeries(t, "RSI(14)", CalcRSI(closes, 14), want.rsi14)
+	// значения из таблицы StockCharts (округление до сотых)
+	rsi := CalcRSI(closes, 14)
+	for i, v := range []float64{70.46, 66.25, 66.48, 69.35, 66.29, 57.92, 62.88, 63.21, 56.01, 62.34} {
+		if math.Abs(rsi[14+i]-v) > 0.005 {
+			t.Errorf("RSI[%d] = %.2f, ожидали %.2f", 14+i, rsi[14+i], v)
+		}
+	}
+	// без изменений цены
+	r, err := NewRSI(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := r.Update(1); v != 0 {
+		t.Errorf("RSI разогрев = %v", v)
+	}
+	flat := CalcRSI([]float64{5, 5, 5, 5}, 2)
+	if flat[3] != 50 {
+		t.Errorf("RSI без изменений = %v, ожидали 50", flat[3])
+	}
+}
+
+func TestMACD(t *testing.T) {
+	result := CalcMACD(closes, 5, 10, 4)
+	macd, signal, hist := make([]float64, len(result)), make([]float64, len(result)), make([]float64, len(result))
+	for i, v := range result {
+		macd[i], signal[i], hist[i] = v.MACD, v.Signal, v.Hist
+	}
+	// TA-Lib выдает MACD только вместе с сигнальной линией
+	equalSeries(t, "MACD", macd, want.macd)
+	equalSeries(t, "Signal", signal, want.signal)
+	equalSeries(t, "Hist", hist, want.hist)
+}
+
+func TestBollinger(t *testing.T) {
+	result := CalcBollinger(closes, 10, 2)
+	middle, upper, lower := make([]float64, len(result)), make([]float64, len(result)), make([]float64, len(result))
+	for i, v := range result {
+		middle[i], upper[i], lower[i] = v.Middle, v.Upper, v.Lower
+	}
+	equalSeries(t, "Bollinger.Middle", middle, want.bbMiddle)
+	equalSeries(t, "Bollinger.Upper", upper, want.bbUpper)
+	equalSeries(t, "Bollinger.Lower", lower, want.bbLower)
+}
+
+func TestATR(t *testing.T) {
+	equalSeries(t, "ATR(5)", CalcATR(testBars, 5), want.atr5)
+}
+
+func TestStochastic(t *testing.T) {
+	result := CalcStochastic(testBars, 5, 3)
+	k, d := make([]float64, len(result)), make([]float64, len(result))
+	for i, v := range result {
+		k[i], d[i] = v.K, v.D
+	}
+	// до готовности %D значения %K не выдаются
+	equalSeries(t, "Stochastic.K", k, want.stochK)
+	equalSeries(t, "Stochastic.D", d, want.stochD)
+}
+
+func TestVWAP(t *testing.T) {
+	// новый день = расчет заново, бар без объема не меняет значение
+	equalSeries(t, "VWAP", CalcVWAP(testBars), want.vwap)
+	if _, ok := NewVWAP().Update(Bar{Time: testBars[0].Time, Close: 1}); ok {
+		t.Error("VWAP без объема должен вернуть false")
+	}
+}
+
+func TestOBV(t *testing.T) {
+	equalSeries(t, "OBV", CalcOBV(testBars), want.obv)
+}
+
+// потоковый и пакетный расчет совпадают
+func TestStreamingMatchesBatch(t *testing.T) {
+	ema, err := NewEMA(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range closes {
+		got, ok := ema.Update(v)
+		if ok == math.IsNaN(want.ema5[i]) || (ok && math.Abs(got-want.ema5[i]) > 1e-8) {
+			t.Fatalf("EMA.Update[%d] = %v %v, ожидали %v", i, got, ok, want.ema5[i])
+		}
+	}
+}
+
+// период <= 0: New* = ErrPeriod, Calc* = ряд из NaN той же длины (без panic)
+func TestPeriod(t *testing.T) {
+	constructors := map[string]func(period int) error{
+		"NewSMA":        func(p int) error { _, err := NewSMA(p); return err },
+		"NewEMA":        func(p int) error { _, err := NewEMA(p); return err },
+		"NewWMA":        func(p int) error { _, err := NewWMA(p); return err },
+		"NewRSI":        func(p int) error { _, err := NewRSI(p); return err },
+		"NewATR":        func(p int) error { _, err := NewATR(p); return err },
+		"NewBollinger":  func(p int) error { _, err := NewBollinger(p, 2); return err },
+		"NewMACD":       func(p int) error { _, err := NewMACD(12, p, 9); return err },
+		"NewStochastic": func(p int) error { _, err := NewStochastic(14, p); return err },
+	}
+	for name, fn := range constructors {
+		for _, period := range []int{0, -1} {
+			if err := fn(period); !errors.Is(err, ErrPeriod) {
+				t.Errorf("%s(%d): %v, ожидали ErrPeriod", name, period, err)
+			}
+		}
+		if err := fn(1); err != nil {
+			t.Errorf("%s(1): %v", name, err)
+		}
+	}
+
+	batches := map[string]func(period int) []float64{
+		"CalcSMA": func(p int) []float64 { return CalcSMA(closes, p) },
+		"CalcEMA": func(p int) []float64 { return CalcEMA(closes, p) },
+		"CalcWMA": func(p int) []float64 { return CalcWMA(closes, p) },
+		"CalcRSI": func(p int) []float64 { return CalcRSI(closes, p) },
+		"CalcATR": func(p int) []float64 { return CalcATR(testBars, p) },
+		"CalcBollinger": func(p int) []float64 {
+			return mapSeries(CalcBollinger(closes, p, 2), func(v BollingerValue) float64 { return v.Upper })
+		},
+		"CalcMACD": func(p int) []float64 {
+			return mapSeries(CalcMACD(closes, 12, p, 9), func(v MACDValue) float64 { return v.Hist })
+		},
+		"CalcStochastic": func(p int) []float64 {
+			return mapSeries(CalcStochastic(testBars, 14, p), func(v StochasticValue) float64 { return v.K })
+		},
+	}
+	for name, fn := range batches {
+		for _, period := range []int{0, -1} {
+			result := fn(period)
+			n := len(closes)
+			if name == "CalcATR" || name == "CalcStochastic" {
+				n = len(testBars)
+			}
+			if len(result) != n {
+				t.Errorf("%s(%d): длина %d, ожидали %d", name, period, len(result), n)
+			}
+			for i, v := range result {
+				if !math.IsNaN(v) {
+					t.Errorf("%s(%d)[%d] = %v, ожидали NaN", name, period, i, v)
+					break
+				}
+			}
+		}
+	}
+}
+
+func mapSeries[T any](in []T, fn func(T) float64) []float64 {
+	result := make([]float64, len(in))
+	for i, v := range in {
+		result[i] = fn(v)
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package indicators
+
+// SMA простая скользящая средняя
+type SMA struct {
+	period int
+	window *window
+	sum    float64
+}
+
+// NewSMA простая скользящая средняя за period значений
+func NewSMA(period int) (*SMA, error) {
+	if err := checkPeriod("NewSMA", period); err != nil {
+		return nil, err
+	}
+	return newSMA(period), nil
+}
+
+func newSMA(period int) *SMA {
+	return &SMA{period: period, window: newWindow(period)}
+}
+
+// Update добавим значение, false = период разогрева
+func (s *SMA) Update(v float64) (float64, bool) {
+	old, full := s.window.push(v)
+	s.sum += v
+	if full {
+		s.sum -= old
+	}
+	if !s.window.full {
+		return 0, false
+	}
+	return s.sum / float64(s.period), true
+}
+
+// CalcSMA простая скользящая средняя по ряду
+func CalcSMA(values []float64, period int) []float64 {
+	s, err := NewSMA(period)
+	if err != nil {
+		return fill(len(values), nan)
+	}
+	return series(values, s.Update, nan)
+}
+
+// EMA экспоненциальная скользящая средняя, alpha = 2 / (period + 1)
+// первое значение = SMA за period значений
+type EMA struct {
+	period int
+	alpha  float64
+	count  int
+	value  float64
+}
+
+// NewEMA экспоненциальная скользящая средняя за period значений
+func NewEMA(period int) (*EMA, error) {
+	if err := checkPeriod("NewEMA", period); err != nil {
+		return nil, err
+	}
+	return newEMA(period), nil
+}
+
+func newEMA(period int) *EMA {
+	return &EMA{period: period, alpha: 2 / float64(period+1)}
+}
+
+// Update добавим значение, false = период разогрева
+func (e *EMA) Update(v float64) (float64, bool) {
+	e.count++
+	if e.count <= e.period {
+		// накапливаем SMA для первого значения
+		e.value += (v - e.value) / float64(e.count)
+		return e.value, e.count == e.period
+	}
+	e.value += e.alpha * (v - e.value)
+	return e.value, true
+}
+
+// CalcEMA экспоненциальная скользящая средняя по ряду
+func CalcEMA(values []float64, period int) []float64 {
+	e, err := NewEMA(period)
+	if err != nil {
+		return fill(len(values), nan)
+	}
+	return series(values, e.Update, nan)
+}
+
+// WMA взвешенная скользящая средняя (веса 1..period, последнее значение с наибольшим весом)
+type WMA struct {
+	period int
+	window *window
+}
+
+// NewWMA взвешенная скользящая средняя за period значений
+func NewWMA(period int) (*WMA, error) {
+	if err := checkPeriod("NewWMA", period); err != nil {
+		return nil, err
+	}
+	return &WMA{period: period, window: newWindow(period)}, nil
+}
+
+// Update добавим значение, false = период разогрева
+func (w *WMA) Update(v float64) (float64, bool) {
+	w.window.push(v)
+	if !w.window.full {
+		return 0, false
+	}
+	var sum float64
+	w.window.each(func(i int, v float64) {
+		sum += float64(i+1) * v
+	})
+	return sum / float64(w.period*(w.period+1)/2), true
+}
+
+// CalcWMA взвешенная скользящая средняя по ряду
+func CalcWMA(values []float64, period int) []float64 {
+	w, err := NewWMA(period)
+	if err != nil {
+		return fill(len(values), nan)
+	}
+	return series(values, w.Update, nan)
+}
@@ -0,0 +1,169 @@
+package indicators
+
+// RSI индекс относительной силы (сглаживание Уайлдера)
+// первое значение после period изменений цены (period + 1 значений)
+type RSI struct {
+	period  int
+	count   int
+	prev    float64
+	avgGain float64
+	avgLoss float64
+}
+
+// NewRSI индекс относительной силы за period значений
+func NewRSI(period int) (*RSI, error) {
+	if err := checkPeriod("NewRSI", period); err != nil {
+		return nil, err
+	}
+	return &RSI{period: period}, nil
+}
+
+// Update добавим значение, false = период разогрева
+func (r *RSI) Update(v float64) (float64, bool) {
+	r.count++
+	if r.count == 1 {
+		r.prev = v
+		return 0, false
+	}
+	change := v - r.prev
+	r.prev = v
+	gain, loss := max(change, 0), max(-change, 0)
+
+	n := float64(r.period)
+	if r.count <= r.period+1 {
+		// первые средние = простое среднее изменений
+		r.avgGain += gain / n
+		r.avgLoss += loss / n
+		if r.count <= r.period {
+			return 0, false
+		}
+	} else {
+		r.avgGain = (r.avgGain*(n-1) + gain) / n
+		r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+	}
+	if r.avgLoss == 0 {
+		if r.avgGain == 0 {
+			return 50, true
+		}
+		return 100, true
+	}
+	return 100 - 100/(1+r.avgGain/r.avgLoss), true
+}
+
+// CalcRSI индекс относительной силы по ряду
+func CalcRSI(values []float64, period int) []float64 {
+	r, err := NewRSI(period)
+	if err != nil {
+		return fill(len(values), nan)
+	}
+	return series(values, r.Update, nan)
+}
+
+// MACDValue значение MACD
+type MACDValue struct {
+	MACD   float64 // EMA(fast) - EMA(slow)
+	Signal float64 // EMA(signal) от MACD
+	Hist   float64 // MACD - Signal
+}
+
+// MACD схождение/расхождение скользящих средних
+// первое значение после slow + signal - 1 значений
+// как в TA-Lib быстрая EMA начинается с SMA последних fast значений до первого значения медленной
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	skip   int // сколько первых значений пропускает быстрая EMA
+	count  int
+}
+
+// NewMACD MACD с периодами fast, slow, signal (классические 12, 26, 9)
+func NewMACD(fast, slow, signal int) (*MACD, error) {
+	if err := checkPeriod("NewMACD", fast, slow, signal); err != nil {
+		return nil, err
+	}
+	return &MACD{fast: newEMA(fast), slow: newEMA(slow), signal: newEMA(signal), skip: max(slow-fast, 0)}, nil
+}
+
+// Update добавим значение, false = период разогрева
+func (m *MACD) Update(v float64) (MACDValue, bool) {
+	m.count++
+	var fast float64
+	if m.count > m.skip {
+		fast, _ = m.fast.Update(v)
+	}
+	slow, ok := m.slow.Update(v)
+	if !ok {
+		return MACDValue{}, false
+	}
+	macd := fast - slow
+	signal, ok := m.signal.Update(macd)
+	if !ok {
+		return MACDValue{}, false
+	}
+	return MACDValue{MACD: macd, Signal: signal, Hist: macd - signal}, true
+}
+
+// CalcMACD MACD по ряду
+func CalcMACD(values []float64, fast, slow, signal int) []MACDValue {
+	empty := MACDValue{MACD: nan, Signal: nan, Hist: nan}
+	m, err := NewMACD(fast, slow, signal)
+	if err != nil {
+		return fill(len(values), empty)
+	}
+	return series(values, m.Update, empty)
+}
+
+// StochasticValue значение стохастика
+type StochasticValue struct {
+	K float64 // %K = (Close - min(Low)) / (max(High) - min(Low)) * 100
+	D float64 // %D = SMA(dPeriod) от %K
+}
+
+// Stochastic стохастический осциллятор
+// первое значение после kPeriod + dPeriod - 1 баров
+type Stochastic struct {
+	highs *window
+	lows  *window
+	d     *SMA
+}
+
+// NewStochastic стохастик с периодами kPeriod, dPeriod (классические 14, 3)
+func NewStochastic(kPeriod, dPeriod int) (*Stochastic, error) {
+	if err := checkPeriod("NewStochastic", kPeriod, dPeriod); err != nil {
+		return nil, err
+	}
+	return &Stochastic{highs: newWindow(kPeriod), lows: newWindow(kPeriod), d: newSMA(dPeriod)}, nil
+}
+
+// Update добавим бар, false = период разогрева
+func (s *Stochastic) Update(bar Bar) (StochasticValue, bool) {
+	s.highs.push(bar.High)
+	s.lows.push(bar.Low)
+	if !s.highs.full {
+		return StochasticValue{}, false
+	}
+	high, low := bar.High, bar.Low
+	s.highs.each(func(_ int, v float64) { high = max(high, v) })
+	s.lows.each(func(_ int, v float64) { low = min(low, v) })
+
+	k := 50.0
+	if high > low {
+		k = (bar.Close - low) / (high - low) * 100
+	}
+	d, ok := s.d.Update(k)
+	if !ok {
+		return StochasticValue{}, false
+	}
+	return StochasticValue{K: k, D: d}, true
+}
+
+// CalcStochastic стохастик по барам
+func CalcStochastic(bars []Bar, kPeriod, dPeriod int) []StochasticValue {
+	empty := StochasticValue{K: nan, D: nan}
+	s, err := NewStochastic(kPeriod, dPeriod)
+	if err != nil {
+		return fill(len(bars), empty)
+	}
+	return series(bars, s.Update, empty)
+}
@@ -0,0 +1,95 @@
+package indicators
+
+import "math"
+
+// ATR средний истинный диапазон (сглаживание Уайлдера)
+// TR = max(High - Low, |High - предыдущий Close|, |Low - предыдущий Close|)
+// у первого бара нет предыдущего Close, первое значение = среднее TR со 2-го по period + 1 бар
+type ATR struct {
+	period    int
+	count     int
+	prevClose float64
+	value     float64
+}
+
+// NewATR средний истинный диапазон за period баров
+func NewATR(period int) (*ATR, error) {
+	if err := checkPeriod("NewATR", period); err != nil {
+		return nil, err
+	}
+	return &ATR{period: period}, nil
+}
+
+// Update добавим бар, false = период разогрева
+func (a *ATR) Update(bar Bar) (float64, bool) {
+	a.count++
+	prevClose := a.prevClose
+	a.prevClose = bar.Close
+	if a.count == 1 {
+		return 0, false
+	}
+	tr := max(bar.High-bar.Low, math.Abs(bar.High-prevClose), math.Abs(bar.Low-prevClose))
+
+	n := float64(a.period)
+	if a.count <= a.period+1 {
+		a.value += tr / n
+		return a.value, a.count == a.period+1
+	}
+	a.value = (a.value*(n-1) + tr) / n
+	return a.value, true
+}
+
+// CalcATR средний истинный диапазон по барам
+func CalcATR(bars []Bar, period int) []float64 {
+	a, err := NewATR(period)
+	if err != nil {
+		return fill(len(bars), nan)
+	}
+	return series(bars, a.Update, nan)
+}
+
+// BollingerValue значение полос Боллинджера
+type BollingerValue struct {
+	Middle float64 // SMA
+	Upper  float64 // SMA + k * std
+	Lower  float64 // SMA - k * std
+}
+
+// Bollinger полосы Боллинджера
+type Bollinger struct {
+	period int
+	k      float64
+	window *window
+}
+
+// NewBollinger полосы Боллинджера за period значений с шириной k стандартных отклонений (классические 20, 2)
+func NewBollinger(period int, k float64) (*Bollinger, error) {
+	if err := checkPeriod("NewBollinger", period); err != nil {
+		return nil, err
+	}
+	return &Bollinger{period: period, k: k, window: newWindow(period)}, nil
+}
+
+// Update добавим значение, false = период разогрева
+func (b *Bollinger) Update(v float64) (BollingerValue, bool) {
+	b.window.push(v)
+	if !b.window.full {
+		return BollingerValue{}, false
+	}
+	var mean, variance float64
+	b.window.each(func(_ int, v float64) { mean += v })
+	mean /= float64(b.period)
+	b.window.each(func(_ int, v float64) { variance += (v - mean) * (v - mean) })
+	std := math.Sqrt(variance / float64(b.period))
+	return BollingerValue{Middle: mean, Upper: mean + b.k*std, Lower: mean - b.k*std}, true
+}
+
+// CalcBollinger полосы Боллинджера по ряду
+func CalcBollinger(values []float64, period int, k float64) []BollingerValue {
+	empty := BollingerValue{Middle: nan, Upper: nan, Lower: nan}
+	b, err := NewBollinger(period, k)
+	if err != nil {
+		return fill(len(values), empty)
+	}
+	return series(values, b.Update, empty)
+}
@@ -0,0 +1,73 @@
+package indicators
+
+import (
+	"time"
+
+	iss "github.com/Ruvad39/go-moex-iss"
+)
+
+// VWAP средневзвешенная по объему цена с начала торгового дня (по московскому времени)
+// цена бара = (High + Low + Close) / 3
+type VWAP struct {
+	day    time.Time
+	sumPV  float64
+	sumVol float64
+}
+
+// NewVWAP средневзвешенная по объему цена
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+// Update добавим бар, false = еще не было объема за день
+func (w *VWAP) Update(bar Bar) (float64, bool) {
+	if day := iss.Interval_D1.Truncate(bar.Time); !day.Equal(w.day) {
+		// новый торговый день
+		w.day = day
+		w.sumPV, w.sumVol = 0, 0
+	}
+	w.sumPV += (bar.High + bar.Low + bar.Close) / 3 * bar.Volume
+	w.sumVol += bar.Volume
+	if w.sumVol == 0 {
+		return 0, false
+	}
+	return w.sumPV / w.sumVol, true
+}
+
+// CalcVWAP средневзвешенная по объему цена по барам
+func CalcVWAP(bars []Bar) []float64 {
+	return series(bars, NewVWAP().Update, nan)
+}
+
+// OBV балансовый объем: объем бара прибавляется при росте Close и вычитается при падении
+// первый бар = 0
+type OBV struct {
+	count     int
+	prevClose float64
+	value     float64
+}
+
+// NewOBV балансовый объем
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+// Update добавим бар
+func (o *OBV) Update(bar Bar) (float64, bool) {
+	o.count++
+	if o.count > 1 {
+		switch {
+		case bar.Close > o.prevClose:
+			o.value += bar.Volume
+		case bar.Close < o.prevClose:
+			o.value -= bar.Volume
+		}
+	}
+	o.prevClose = bar.Close
+	return o.value, true
+}
+
+// CalcOBV балансовый объем по барам
+func CalcOBV(bars []Bar) []float64 {
+	return series(bars, NewOBV().Update, nan)
+}
@@ -0,0 +1,150 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInterval не поддерживаемый интервал свечей
+var ErrInterval = errors.New("не поддерживаемый интервал свечей")
+
+// Interval интервал свечей (значение параметра interval в запросе iss)
+type Interval int
+
+// Доступные интервалы свечей.
+const (
+	Interval_M1  Interval = 1
+	Interval_M10 Interval = 10
+	Interval_H1  Interval = 60
+	Interval_D1  Interval = 24
+	Interval_W1  Interval = 7
+	Interval_MN1 Interval = 31
+	Interval_Q1  Interval = 4
+)
+
+// IsValid интервал поддерживается iss
+func (i Interval) IsValid() bool {
+	switch i {
+	case Interval_M1, Interval_M10, Interval_H1, Interval_D1, Interval_W1, Interval_MN1, Interval_Q1:
+		return true
+	}
+	return false
+}
+
+// String название интервала: M1 M10 H1 D1 W1 MN1 Q1
+func (i Interval) String() string {
+	switch i {
+	case Interval_M1:
+		return "M1"
+	case Interval_M10:
+		return "M10"
+	case Interval_H1:
+		return "H1"
+	case Interval_D1:
+		return "D1"
+	case Interval_W1:
+		return "W1"
+	case Interval_MN1:
+		return "MN1"
+	case Interval_Q1:
+		return "Q1"
+
+	}
+	return "неизвестно"
+}
+
+// Duration длительность интервала
+// для MN1 и Q1 длительность приблизительная (30 и 91 день), точное начало периода = Truncate
+func (i Interval) Duration() time.Duration {
+	switch i {
+	case Interval_M1:
+		return time.Minute
+	case Interval_M10:
+		return 10 * time.Minute
+	case Interval_H1:
+		return time.Hour
+	case Interval_D1:
+		return 24 * time.Hour
+	case Interval_W1:
+		return 7 * 24 * time.Hour
+	case Interval_MN1:
+		return 30 * 24 * time.Hour
+	case Interval_Q1:
+		return 91 * 24 * time.Hour
+	}
+	return 0
+}
+
+// Truncate время начала свечи, в которую попадает t (по московскому времени)
+// W1 = понедельник, MN1 = первое число месяца, Q1 = первое число квартала
+func (i Interval) Truncate(t time.Time) time.Time {
+	t = t.In(TzMsk)
+	year, month, day := t.Date()
+	switch i {
+	case Interval_M1, Interval_M10, Interval_H1:
+		dayStart := time.Date(year, month, day, 0, 0, 0, 0, TzMsk)
+		return dayStart.Add(t.Sub(dayStart).Truncate(i.Duration()))
+	case Interval_D1:
+		return time.Date(year, month, day, 0, 0, 0, 0, TzMsk)
+	case Interval_W1:
+		// понедельник = 0
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, TzMsk)
+	case Interval_MN1:
+		return time.Date(year, month, 1, 0, 0, 0, 0, TzMsk)
+	case Interval_Q1:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, TzMsk)
+	}
+	return t
+}
+
+// MarshalText для json и других текстовых форматов: "D1"
+func (i Interval) MarshalText() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("%w: %d", ErrInterval, int(i))
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText для json и других текстовых форматов: "D1" или "24"
+func (i *Interval) UnmarshalText(text []byte) error {
+	v, err := ParseInterval(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+
+// ParseInterval
+// M1 M10 H1 D1 W1 MN1 Q1 (без учета регистра) или значение параметра iss (1 10 60 24 7 31 4)
+func ParseInterval(input string) (Interval, error) {
+	switch strings.ToUpper(strings.TrimSpace(input)) {
+	case "M1":
+		return Interval_M1, nil
+	case "M10":
+		return Interval_M10, nil
+	case "H1":
+		return Interval_H1, nil
+	case "D1":
+		return Interval_D1, nil
+	case "W1":
+		return Interval_W1, nil
+	case "MN1":
+		return Interval_MN1, nil
+	case "Q1":
+		return Interval_Q1, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(input)); err == nil && Interval(n).IsValid() {
+		return Interval(n), nil
+	}
+	return -1, fmt.Errorf("%w: не поддерживаемый формат периода свечи %s", ErrInterval, input)
+}
+
+// IntervalToString конвертация Interval свечей  в строку
+func IntervalToString(i Interval) string {
+	return i.String()
+}
@@ -0,0 +1,110 @@
+package iss
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Interval
+		err   bool
+	}{
+		{"M1", Interval_M1, false},
+		{"m10", Interval_M10, false},
+		{" H1 ", Interval_H1, false},
+		{"D1", Interval_D1, false},
+		{"w1", Interval_W1, false},
+		{"MN1", Interval_MN1, false},
+		{"Q1", Interval_Q1, false},
+		{"24", Interval_D1, false},
+		{"60", Interval_H1, false},
+		{"31", Interval_MN1, false},
+		{"", 0, true},
+		{"M5", 0, true},
+		{"H4", 0, true},
+		{"5", 0, true},
+		{"-1", 0, true},
+		{"D", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseInterval(tt.input)
+		if tt.err {
+			if !errors.Is(err, ErrInterval) {
+				t.Errorf("ParseInterval(%q) = %v, %v, ожидали ErrInterval", tt.input, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseInterval(%q) = %v, %v, ожидали %v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestIntervalText(t *testing.T) {
+	var v struct {
+		Interval Interval `json:"interval"`
+	}
+	if err := json.Unmarshal([]byte(`{"interval": "H1"}`), &v); err != nil || v.Interval != Interval_H1 {
+		t.Fatalf("Unmarshal H1: %v %v", v.Interval, err)
+	}
+	if err := json.Unmarshal([]byte(`{"interval": "7"}`), &v); err != nil || v.Interval != Interval_W1 {
+		t.Fatalf("Unmarshal 7: %v %v", v.Interval, err)
+	}
+	if err := json.Unmarshal([]byte(`{"interval": "M5"}`), &v); !errors.Is(err, ErrInterval) {
+		t.Fatalf("Unmarshal M5: %v", err)
+	}
+	v.Interval = Interval_MN1
+	body, err := json.Marshal(v)
+	if err != nil || string(body) != `{"interval":"MN1"}` {
+		t.Fatalf("Marshal: %s %v", body, err)
+	}
+	v.Interval = 5
+	if _, err = json.Marshal(v); !errors.Is(err, ErrInterval) {
+		t.Fatalf("Marshal 5: %v", err)
+	}
+}
+
+func TestIntervalTruncate(t *testing.T) {
+	msk := func(s string) time.Time {
+		v, err := time.ParseInLocation(layout, s, TzMsk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		interval Interval
+		t        time.Time
+		want     string
+	}{
+		{Interval_M1, msk("2024-08-07 10:15:42"), "2024-08-07 10:15:00"},
+		{Interval_M10, msk("2024-08-07 10:19:59"), "2024-08-07 10:10:00"},
+		{Interval_H1, msk("2024-08-07 10:59:59"), "2024-08-07 10:00:00"},
+		{Interval_D1, msk("2024-08-07 23:59:59"), "2024-08-07 00:00:00"},
+		// 22:30 utc = 01:30 следующего дня по москве
+		{Interval_D1, time.Date(2024, 8, 7, 22, 30, 0, 0, time.UTC), "2024-08-08 00:00:00"},
+		{Interval_M1, time.Date(2024, 8, 7, 22, 30, 31, 0, time.UTC), "2024-08-08 01:30:00"},
+		// неделя начинается с понедельника
+		{Interval_W1, msk("2024-08-07 10:00:00"), "2024-08-05 00:00:00"},
+		{Interval_W1, msk("2024-08-05 00:00:00"), "2024-08-05 00:00:00"},
+		{Interval_W1, msk("2024-08-11 23:59:59"), "2024-08-05 00:00:00"},
+		// воскресенье 23:00 utc = понедельник по москве
+		{Interval_W1, time.Date(2024, 8, 11, 23, 0, 0, 0, time.UTC), "2024-08-12 00:00:00"},
+		// неделя через границу месяца
+		{Interval_W1, msk("2024-09-01 12:00:00"), "2024-08-26 00:00:00"},
+		{Interval_MN1, msk("2024-08-31 23:59:59"), "2024-08-01 00:00:00"},
+		{Interval_MN1, time.Date(2024, 8, 31, 21, 0, 0, 0, time.UTC), "2024-09-01 00:00:00"},
+		{Interval_Q1, msk("2024-08-15 12:00:00"), "2024-07-01 00:00:00"},
+		{Interval_Q1, msk("2024-03-31 12:00:00"), "2024-01-01 00:00:00"},
+	}
+	for _, tt := range tests {
+		got := tt.interval.Truncate(tt.t)
+		if got.Location() != TzMsk || got.Format(layout) != tt.want {
+			t.Errorf("%v.Truncate(%v) = %v, ожидали %s", tt.interval, tt.t, got, tt.want)
+		}
+	}
+}
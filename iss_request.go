@@ -2,15 +2,24 @@ package iss
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
 	"path"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
-	FortsBoard   = "RFUD" // фьючерсы
-	StockBoard   = "TQBR" // акция
-	OptionsBoard = "ROPD" // опционы
+	FortsBoard    = "RFUD" // фьючерсы
+	StockBoard    = "TQBR" // акция
+	OptionsBoard  = "ROPD" // опционы
+	ETFBoard      = "TQTF" // фонды (ETF, БПИФ)
+	OFZBoard      = "TQOB" // государственные облигации (ОФЗ)
+	BondsBoard    = "TQCB" // корпоративные облигации
+	CurrencyBoard = "CETS" // валюта
+	IndexBoard    = "SNDX" // индексы
 
 	AlgoPackStock = "eq" // акции
 	AlgoPackForts = "fo" // фьючерсы
@@ -20,8 +29,31 @@ const (
 var (
 	// EOF обозначает конец выгрузки
 	EOF = errors.New("end of data")
+	// ErrDateFormat не верный формат даты
+	ErrDateFormat = errors.New("не верный формат даты (нужно 2006-01-02 или 2006-01-02 15:04:05)")
+	// ErrDateRange дата from больше даты till
+	ErrDateRange = errors.New("дата from больше даты till")
+	// ErrFutureDate дата в будущем для исторических данных
+	ErrFutureDate = errors.New("дата в будущем для исторических данных")
+	// ErrParam параметр не поддерживается запросом или имеет не верное значение
+	ErrParam = errors.New("параметр не поддерживается запросом")
 )
 
+// Порядок сортировки sort_order
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// MaxLimit максимальное значение параметра limit
+const MaxLimit = 100
+
+// MinQueryLen минимальная длина строки поиска q
+const MinQueryLen = 3
+
+// формат даты в строке запроса (дата и время = layout)
+const dateLayout = "2006-01-02"
+
 // IssRequest построитель запроса  iss-moex
 type IssRequest struct {
 	history    bool   // /history – данные итогов торгов.
@@ -33,24 +65,47 @@ type IssRequest struct {
 	target     string //
 	format     string // формат данных json xml csv
 	// параметры запроса
-	symbols         string // список инструментов для строки запроса
-	iss_only        string // iss.only=block1,block2 = ответ может содержать несколько блоков данных и этот
-	metadata        bool   // iss.meta=on|off  = включать или нет метаинформацию
-	jsonFull        bool   // iss.json=compact|extended сокращенный или  расширенный  формат json;
-	dateFrom        string // дата from
-	dateTo          string // дата till
-	date            string // дата date
-	interval        int    // Интервал свечек
-	start           int    // start =
-	q               string // Поиск инструмента по части Кода, Названию, ISIN, Идентификатору Эмитента, Номеру гос.регистрации.
-	algoPack        string // тип данных алгопака
-	algoPackMarkets string // рынок для  алгопака eq = акции fo = фьючерсы	fx = валюта
-	latest          bool   // Super Candles флаг latest=1 возвращает последнюю пятиминутку за указанную дату
-	algoPackStock   bool   //
-	algoPackForts   bool   //
-	algoPackFx      bool   //
+	symbols         string              // список инструментов для строки запроса
+	iss_only        string              // iss.only=block1,block2 = ответ может содержать несколько блоков данных и этот
+	metadata        bool                // iss.meta=on|off  = включать или нет метаинформацию
+	jsonFull        bool                // iss.json=compact|extended сокращенный или  расширенный  формат json;
+	dateFrom        string              // дата from
+	dateTo          string              // дата till
+	date            string              // дата date
+	interval        Interval            // Интервал свечек
+	start           int                 // start =
+	q               string              // Поиск инструмента по части Кода, Названию, ISIN, Идентификатору Эмитента, Номеру гос.регистрации.
+	algoPack        string              // тип данных алгопака
+	algoPackMarkets string              // рынок для  алгопака eq = акции fo = фьючерсы	fx = валюта
+	latest          bool                // Super Candles флаг latest=1 возвращает последнюю пятиминутку за указанную дату
+	algoPackStock   bool                //
+	algoPackForts   bool                //
+	algoPackFx      bool                //
+	columns         map[string][]string // <block>.columns= список колонок по блокам
+	params          url.Values          // дополнительные параметры (сортировка, фильтры)
+}
+
+// clone копия запроса. Все методы построителя возвращают новую копию,
+// поэтому один IssRequest можно использовать из нескольких горутин
+func (u *IssRequest) clone() *IssRequest {
+	c := *u
+	if u.columns != nil {
+		c.columns = make(map[string][]string, len(u.columns))
+		for block, cols := range u.columns {
+			c.columns[block] = append([]string(nil), cols...)
+		}
+	}
+	if u.params != nil {
+		c.params = make(url.Values, len(u.params))
+		for key, values := range u.params {
+			c.params[key] = append([]string(nil), values...)
+		}
+	}
+	return &c
 }
 
+// NewIssRequest создадим построитель запроса
+// IssRequest не изменяемый: каждый метод построителя возвращает новую копию
 func NewIssRequest() *IssRequest {
 	// значение по умолчанию
 	u := &IssRequest{
@@ -106,15 +161,11 @@ func (u *IssRequest) URL() string {
 		_url.Path = path.Join(_url.Path, u.symbol)
 	}
 	if u.target != "" {
-		target := u.target
-		if u.format != "" {
-			target = target + "." + u.format
-		}
-		_url.Path = path.Join(_url.Path, target)
+		_url.Path = path.Join(_url.Path, u.target)
 	}
-	// все равно проставим формат
-	if u.target == "" {
-		_url.Path = path.Join(_url.Path, "."+u.format)
+	// формат = расширение последнего сегмента: securities/SBER.json, tradestats.json
+	if u.format != "" {
+		_url.Path = strings.TrimSuffix(_url.Path, "/") + "." + u.format
 	}
 
 	// создаем параметры
@@ -130,24 +181,25 @@ func (u *IssRequest) URL() string {
 		//iss.json=compact|extended
 		q.Set("iss.json", "extended")
 	}
-	// нет проверки на формат даты
+	// формат даты проверяется в Validate
 	if u.date != "" {
 		q.Set("date", u.date)
 	}
-	// нет проверки на формат даты
 	if u.dateFrom != "" {
 		q.Set("from", u.dateFrom)
 	}
-	// нет проверки на формат даты
 	if u.dateTo != "" {
 		q.Set("till", u.dateTo)
 	}
 	if u.interval != 0 {
-		q.Set("interval", strconv.Itoa(u.interval))
+		q.Set("interval", strconv.Itoa(int(u.interval)))
 	}
 	if u.start != 0 {
 		q.Set("start", strconv.Itoa(u.start))
 	}
+	if u.q != "" {
+		q.Set("q", u.q)
+	}
 	// если не пустой список инструментов
 	if u.symbols != "" && u.target != "candles" {
 		q.Set("securities", u.symbols)
@@ -155,11 +207,19 @@ func (u *IssRequest) URL() string {
 	if u.latest {
 		q.Set("latest", "1")
 	}
-	// // если не пустой список колонок
-	// //q.Set("securities.columns",  params.columns)
-	// if params.columns != "" {
-	// 	q.Set(params.iss_only+".columns", params.columns)
-	// }
+	// если не пустой список колонок
+	for block, cols := range u.columns {
+		if len(cols) > 0 {
+			q.Set(block+".columns", strings.Join(cols, ","))
+		}
+	}
+
+	// дополнительные параметры (встроенные параметры не переопределяют)
+	for key, values := range u.params {
+		if !isReservedParam(key) {
+			q[key] = values
+		}
+	}
 
 	// добавляем к URL параметры
 	_url.RawQuery = q.Encode()
@@ -169,12 +229,14 @@ func (u *IssRequest) URL() string {
 
 // History
 func (u *IssRequest) History() *IssRequest {
+	u = u.clone()
 	u.history = true
 	return u
 }
 
 // Stock проставим параметры для акций
 func (u *IssRequest) Stock() *IssRequest {
+	u = u.clone()
 	u.engines = "stock"
 	u.markets = "shares"
 	u.boards = StockBoard
@@ -184,6 +246,7 @@ func (u *IssRequest) Stock() *IssRequest {
 
 // Forts проставим параметры для фьючерсов
 func (u *IssRequest) Forts() *IssRequest {
+	u = u.clone()
 	u.engines = "futures"
 	u.markets = "forts"
 	u.target = "securities"
@@ -193,6 +256,7 @@ func (u *IssRequest) Forts() *IssRequest {
 
 // Options проставим параметры для опционов
 func (u *IssRequest) Options() *IssRequest {
+	u = u.clone()
 	u.engines = "futures"
 	u.markets = "options"
 	u.target = "securities"
@@ -204,6 +268,7 @@ func (u *IssRequest) Options() *IssRequest {
 
 // Bondsпроставим параметры для Bonds
 func (u *IssRequest) Bonds() *IssRequest {
+	u = u.clone()
 	u.engines = "stock"
 	u.markets = "bonds"
 	u.target = "securities"
@@ -213,6 +278,7 @@ func (u *IssRequest) Bonds() *IssRequest {
 
 // Candle проставим параметры для запроса свечи
 func (u *IssRequest) Candle() *IssRequest {
+	u = u.clone()
 	u.target = "candles"
 	u.securities = true
 	return u
@@ -220,24 +286,28 @@ func (u *IssRequest) Candle() *IssRequest {
 
 // Engines /engines/(trade_engine_name)
 func (u *IssRequest) Engines(param string) *IssRequest {
+	u = u.clone()
 	u.engines = param
 	return u
 }
 
 // Markets /markets/(market_name)
 func (u *IssRequest) Markets(param string) *IssRequest {
+	u = u.clone()
 	u.markets = param
 	return u
 }
 
 // Boards /boards/(boardid)
 func (u *IssRequest) Boards(param string) *IssRequest {
+	u = u.clone()
 	u.boards = param
 	return u
 }
 
 // Target
 func (u *IssRequest) Target(param string) *IssRequest {
+	u = u.clone()
 	u.target = param
 	//если target == candles всегда вставляем securities
 	if param == "candles" {
@@ -248,28 +318,33 @@ func (u *IssRequest) Target(param string) *IssRequest {
 
 // WithSecurities добавлять securities в строку
 func (u *IssRequest) WithSecurities(param bool) *IssRequest {
+	u = u.clone()
 	u.securities = param
 	return u
 }
 
-// Json проставим формат данных: json xml
+// Json проставим формат данных: json
 func (u *IssRequest) Json() *IssRequest {
+	u = u.clone()
 	u.format = "json"
 	return u
 }
 
-//// Xml проставим формат данных: xml
-//func (u *IssRequest) Xml() *IssRequest {
-//	u.format = "xml"
-//	return u
-//}
-//
-//// формат данных: csv
-//func (u *IssRequest) Csv() *IssRequest {
-//	u.format = "csv"
-//	return u
-//}
-//
+// Xml проставим формат данных: xml
+func (u *IssRequest) Xml() *IssRequest {
+	u = u.clone()
+	u.format = "xml"
+	return u
+}
+
+// Csv проставим формат данных: csv
+// разделитель ";" кодировка cp1251
+func (u *IssRequest) Csv() *IssRequest {
+	u = u.clone()
+	u.format = "csv"
+	return u
+}
+
 //// формат данных: html
 //func (u *IssRequest) Html() *IssRequest {
 //	u.format = "html"
@@ -280,111 +355,402 @@ func (u *IssRequest) Json() *IssRequest {
 // тип данных и размер полей (столбцов или атрибутов xml)
 // iss.meta=on|off
 func (u *IssRequest) MetaData(param bool) *IssRequest {
+	u = u.clone()
 	u.metadata = param
 	return u
 }
 
-// JsonFull расширенный формат json
+// JsonFull расширенный формат json (массив объектов вместо columns + data)
 // iss.json=extended
 func (u *IssRequest) JsonFull() *IssRequest {
+	u = u.clone()
 	u.jsonFull = true
 	return u
 }
 
 // Only iss.only=
 func (u *IssRequest) Only(param string) *IssRequest {
+	u = u.clone()
 	u.iss_only = param
 	return u
 }
 
 // OnlySecurities iss.only=securities
 func (u *IssRequest) OnlySecurities() *IssRequest {
+	u = u.clone()
 	u.iss_only = "securities"
 	return u
 }
 
 // OnlyMarketData iss.only=marketdata
 func (u *IssRequest) OnlyMarketData() *IssRequest {
+	u = u.clone()
 	u.iss_only = "marketdata"
 	return u
 }
 
 // MarketData iss.only=marketdata
 func (u *IssRequest) MarketData() *IssRequest {
+	u = u.clone()
 	u.iss_only = "marketdata"
 	return u
 }
 
+// Columns список колонок которые нужно вернуть в блоке
+// <block>.columns=col1,col2
+func (u *IssRequest) Columns(block string, cols ...string) *IssRequest {
+	u = u.clone()
+	if u.columns == nil {
+		u.columns = make(map[string][]string)
+	}
+	u.columns[block] = cols
+	return u
+}
+
+// ColumnsOf вернуть в блоке только те колонки, которые есть в структуре v
+// (по тегу json). v = структура, ссылка или слайс структур
+func (u *IssRequest) ColumnsOf(block string, v interface{}) *IssRequest {
+	return u.Columns(block, StructColumns(v)...)
+}
+
+// StructColumns список колонок, которые использует структура (по тегу json)
+// поля без тега и с тегом "-" пропускаются
+func StructColumns(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	cols := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get(DefaultTagKey), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		cols = append(cols, name)
+	}
+	return cols
+}
+
 func (u *IssRequest) Start(param int) *IssRequest {
+	u = u.clone()
 	u.start = param
 	return u
 }
 
-// From нет проверки на формат даты
+// From дата from строкой (2006-01-02 или 2006-01-02 15:04:05)
+// формат проверяется в Validate
 func (u *IssRequest) From(param string) *IssRequest {
+	u = u.clone()
 	u.dateFrom = param
 	return u
 }
 
-// To нет проверки на формат даты
+// To дата till строкой (2006-01-02 или 2006-01-02 15:04:05)
+// формат проверяется в Validate
 func (u *IssRequest) To(param string) *IssRequest {
+	u = u.clone()
 	u.dateTo = param
 	return u
 }
 
-// Date нет проверки на формат даты
+// Date дата date строкой (2006-01-02)
+// формат проверяется в Validate
 func (u *IssRequest) Date(param string) *IssRequest {
+	u = u.clone()
 	u.date = param
 	return u
 }
 
+// FromTime дата from. Пустое время = параметр не передаем
+func (u *IssRequest) FromTime(param time.Time) *IssRequest {
+	u = u.clone()
+	u.dateFrom = FormatDate(param)
+	return u
+}
+
+// TillTime дата till. Пустое время = параметр не передаем
+func (u *IssRequest) TillTime(param time.Time) *IssRequest {
+	u = u.clone()
+	u.dateTo = FormatDate(param)
+	return u
+}
+
+// OnDate дата date (время отбрасывается). Пустое время = параметр не передаем
+func (u *IssRequest) OnDate(param time.Time) *IssRequest {
+	u = u.clone()
+	u.date = ""
+	if !param.IsZero() {
+		u.date = param.In(TzMsk).Format(dateLayout)
+	}
+	return u
+}
+
+// DateRange период from - till
+func (u *IssRequest) DateRange(from, till time.Time) *IssRequest {
+	return u.FromTime(from).TillTime(till)
+}
+
+// Validate проверим параметры запроса
+// формат дат, from <= till, для исторических данных (history, свечи, алгопак) даты from и date не в будущем,
+// интервал свечей, дополнительные параметры (сортировка, limit, фильтры) допустимы для данного запроса
+func (u *IssRequest) Validate() error {
+	from, err := ParseDate(u.dateFrom)
+	if err != nil {
+		return err
+	}
+	till, err := ParseDate(u.dateTo)
+	if err != nil {
+		return err
+	}
+	date, err := ParseDate(u.date)
+	if err != nil {
+		return err
+	}
+	if u.interval != 0 && !u.interval.IsValid() {
+		return fmt.Errorf("%w: %d", ErrInterval, int(u.interval))
+	}
+	// till без времени = до конца дня (так считает iss)
+	if !till.IsZero() && isDateOnly(u.dateTo) {
+		till = till.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if !from.IsZero() && !till.IsZero() && from.After(till) {
+		return fmt.Errorf("%w: %s > %s", ErrDateRange, u.dateFrom, u.dateTo)
+	}
+	if u.q != "" && len([]rune(u.q)) < MinQueryLen {
+		return fmt.Errorf("%w: q=%s (нужно не меньше %d символов)", ErrParam, u.q, MinQueryLen)
+	}
+	if err = u.validateParams(); err != nil {
+		return err
+	}
+	if u.isHistorical() {
+		now := time.Now()
+		if from.After(now) {
+			return fmt.Errorf("%w: from=%s", ErrFutureDate, u.dateFrom)
+		}
+		if date.After(now) {
+			return fmt.Errorf("%w: date=%s", ErrFutureDate, u.date)
+		}
+	}
+	return nil
+}
+
+// isHistorical запрос исторических данных: history, свечи, алгопак
+func (u *IssRequest) isHistorical() bool {
+	return u.history || u.target == "candles" || u.algoPack != ""
+}
+
+// isDateOnly дата без времени (2006-01-02)
+func isDateOnly(s string) bool {
+	return len(strings.TrimSpace(s)) == len(dateLayout)
+}
+
+// reservedParams параметры, которые задаются методами построителя
+var reservedParams = map[string]bool{
+	"iss.meta":   true,
+	"iss.only":   true,
+	"iss.json":   true,
+	"date":       true,
+	"from":       true,
+	"till":       true,
+	"interval":   true,
+	"start":      true,
+	"q":          true,
+	"securities": true,
+	"latest":     true,
+}
+
+// isReservedParam параметр задается методом построителя (в том числе <block>.columns)
+func isReservedParam(key string) bool {
+	return reservedParams[key] || strings.HasSuffix(key, ".columns")
+}
+
+// Param произвольный параметр строки запроса
+// встроенные параметры (from, till, start, interval, iss.only ...) задаются только своими методами,
+// Validate вернет ErrParam
+func (u *IssRequest) Param(key string, value interface{}) *IssRequest {
+	u = u.clone()
+	if u.params == nil {
+		u.params = url.Values{}
+	}
+	u.params.Set(key, fmt.Sprintf("%v", value))
+	return u
+}
+
+// Sort сортировка по колонке: sort_column= sort_order=asc|desc
+func (u *IssRequest) Sort(column, order string) *IssRequest {
+	return u.Param("sort_column", column).Param("sort_order", order)
+}
+
+// Limit количество записей на странице: limit= (не больше MaxLimit)
+func (u *IssRequest) Limit(param int) *IssRequest {
+	return u.Param("limit", param)
+}
+
+// PrimaryBoard только основной режим торгов инструмента: primary_board=1
+func (u *IssRequest) PrimaryBoard(param bool) *IssRequest {
+	return u.Param("primary_board", boolParam(param))
+}
+
+// Assets фильтр по кодам базового актива (для срочного рынка): assets=Si,RI
+func (u *IssRequest) Assets(codes ...string) *IssRequest {
+	return u.Param("assets", strings.Join(codes, ","))
+}
+
+// MarketPriceBoard только режим, в котором рассчитывается рыночная цена: marketprice_board=1
+func (u *IssRequest) MarketPriceBoard(param bool) *IssRequest {
+	return u.Param("marketprice_board", boolParam(param))
+}
+
+// SecuritiesStatus фильтр по статусу инструмента: securities.status=A
+// A = торгуется, N = не торгуется
+func (u *IssRequest) SecuritiesStatus(status string) *IssRequest {
+	return u.Param("securities.status", status)
+}
+
+func boolParam(param bool) int {
+	if param {
+		return 1
+	}
+	return 0
+}
+
+// isList запрос списка инструментов или истории (а не свечей, стакана и тд)
+func (u *IssRequest) isList() bool {
+	return u.target == "securities" || u.history || (u.securities && u.target == "")
+}
+
+// validateParams проверим дополнительные параметры для данного запроса
+func (u *IssRequest) validateParams() error {
+	for key := range u.params {
+		value := u.params.Get(key)
+		if isReservedParam(key) {
+			return fmt.Errorf("%w: %s=%s (задается методом построителя)", ErrParam, key, value)
+		}
+		var ok bool
+		switch key {
+		case "sort_column":
+			ok = u.isList() && value != ""
+		case "sort_order":
+			ok = u.isList() && (value == SortAsc || value == SortDesc)
+		case "limit":
+			n, err := strconv.Atoi(value)
+			ok = u.isList() && err == nil && n > 0 && n <= MaxLimit
+		case "primary_board":
+			// только на уровне рынка (без режима торгов)
+			ok = u.isList() && u.boards == ""
+		case "assets":
+			ok = u.isList() && u.engines == "futures"
+		case "marketprice_board":
+			ok = u.isList() && (u.engines == "" || u.engines == "stock")
+		case "securities.status":
+			ok = u.isList()
+		default:
+			// параметры заданные через Param не проверяем
+			ok = true
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s=%s", ErrParam, key, value)
+		}
+	}
+	return nil
+}
+
+// FormatDate дата для строки запроса по московскому времени
+// если время = 00:00:00 то только дата. Пустое время = пустая строка
+func FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	t = t.In(TzMsk)
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format(dateLayout)
+	}
+	return t.Format(layout)
+}
+
+// ParseDate распарсим дату из строки запроса (по московскому времени)
+// пустая строка = пустое время
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, s, TzMsk); err == nil {
+		return t, nil
+	}
+	// встречается и двойной пробел между датой и временем
+	if t, err := time.ParseInLocation(layout, strings.Join(strings.Fields(s), " "), TzMsk); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %s", ErrDateFormat, s)
+}
+
+// Query q= поиск инструмента по части кода, названию, ISIN, идентификатору эмитента, номеру гос. регистрации
+func (u *IssRequest) Query(param string) *IssRequest {
+	u = u.clone()
+	u.q = param
+	return u
+}
+
 // Symbols список символов в строке запроса
 func (u *IssRequest) Symbols(param string) *IssRequest {
+	u = u.clone()
 	u.symbols = param
 	return u
 }
 
 // Symbol один символ. после securities
 func (u *IssRequest) Symbol(param string) *IssRequest {
+	u = u.clone()
 	u.symbol = param
 	return u
 }
 
 // Interval
-func (u *IssRequest) Interval(param int) *IssRequest {
+func (u *IssRequest) Interval(param Interval) *IssRequest {
+	u = u.clone()
 	u.interval = param
 	return u
 }
 
 // что выбираем из алгопака
 func (u *IssRequest) AlgoPack(param string) *IssRequest {
+	u = u.clone()
 	u.algoPack = param
 	return u
 }
 
 // какой рынок в алгопаке
 func (u *IssRequest) AlgoPackMarkets(param string) *IssRequest {
+	u = u.clone()
 	u.algoPackMarkets = param
 	return u
 }
 
 // флаг latest=1 возвращает последнюю пятиминутку за указанный период
 func (u *IssRequest) Latest(param bool) *IssRequest {
+	u = u.clone()
 	u.latest = param
 	return u
 }
 
 func (u *IssRequest) AlgoPackStock(param bool) *IssRequest {
+	u = u.clone()
 	u.algoPackStock = param
 	return u
 }
 
 func (u *IssRequest) AlgoPackForts(param bool) *IssRequest {
+	u = u.clone()
 	u.algoPackForts = param
 	return u
 }
 
 func (u *IssRequest) AlgoPackFx(param bool) *IssRequest {
+	u = u.clone()
 	u.algoPackFx = param
 	return u
 }
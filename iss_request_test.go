@@ -0,0 +1,166 @@
+package iss
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateDates(t *testing.T) {
+	now := time.Now().In(TzMsk)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, TzMsk)
+	tomorrow := today.AddDate(0, 0, 1)
+	candles := NewIssRequest().Candle().Engines("stock").Markets("shares").Boards(StockBoard).Symbol("SBER").Interval(Interval_H1)
+	history := NewIssRequest().History().Stock()
+	tradestats := NewIssRequest().AlgoPackMarkets(AlgoPackStock).AlgoPack("tradestats")
+
+	tests := []struct {
+		name string
+		req  *IssRequest
+		err  error
+	}{
+		{"пустые даты", candles, nil},
+		{"from < till", candles.From("2024-08-01").To("2024-08-02"), nil},
+		{"from = till без времени", candles.From("2024-08-01").To("2024-08-01"), nil},
+		{"from со временем в тот же день что till без времени", candles.From("2024-08-01 10:00:00").To("2024-08-01"), nil},
+		{"FromTime сегодня 10:00 TillTime сегодня", candles.FromTime(today.Add(10 * time.Hour)).TillTime(today), nil},
+		{"from > till", candles.From("2024-08-02").To("2024-08-01"), ErrDateRange},
+		{"from > till со временем", candles.From("2024-08-01 12:00:00").To("2024-08-01 11:00:00"), ErrDateRange},
+		{"формат даты", candles.From("01.08.2024"), ErrDateFormat},
+		{"двойной пробел", candles.From("2024-08-01  10:00:00"), nil},
+		{"history from в будущем", history.FromTime(tomorrow), ErrFutureDate},
+		{"history date в будущем", history.OnDate(tomorrow), ErrFutureDate},
+		{"history date сегодня", history.OnDate(now), nil},
+		{"свечи from в будущем", candles.FromTime(now.Add(2 * time.Hour)), ErrFutureDate},
+		{"свечи till в будущем", candles.FromTime(today).TillTime(tomorrow), nil},
+		{"tradestats date в будущем", tradestats.OnDate(tomorrow), ErrFutureDate},
+		{"tradestats from сегодня", tradestats.FromTime(today), nil},
+		{"marketdata from в будущем", NewIssRequest().Stock().FromTime(tomorrow), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.err == nil && err != nil {
+				t.Fatalf("ошибка %v", err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("ошибка %v, ожидали %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, ""},
+		{time.Date(2024, 8, 1, 0, 0, 0, 0, TzMsk), "2024-08-01"},
+		{time.Date(2024, 8, 1, 10, 30, 0, 0, TzMsk), "2024-08-01 10:30:00"},
+		// utc переводится в москву
+		{time.Date(2024, 8, 1, 7, 0, 0, 0, time.UTC), "2024-08-01 10:00:00"},
+		{time.Date(2024, 7, 31, 21, 0, 0, 0, time.UTC), "2024-08-01"},
+	}
+	for _, tt := range tests {
+		if got := FormatDate(tt.t); got != tt.want {
+			t.Errorf("FormatDate(%v) = %q, ожидали %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestParamReserved(t *testing.T) {
+	base := NewIssRequest().History().Stock().From("2024-08-01")
+	for _, key := range []string{"start", "from", "till", "interval", "iss.only", "iss.meta", "q", "date", "securities", "history.columns"} {
+		req := base.Param(key, "1")
+		if err := req.Validate(); !errors.Is(err, ErrParam) {
+			t.Errorf("Param(%q): ошибка %v, ожидали ErrParam", key, err)
+		}
+		// даже без Validate встроенный параметр не переопределяется
+		if parsed, err := ParseIssURL(req.URL()); err != nil || parsed.dateFrom != "2024-08-01" || parsed.start != 0 {
+			t.Errorf("Param(%q): url %s", key, req.URL())
+		}
+	}
+	if err := base.Param("lang", "en").Param("securities.status", "traded").Validate(); err != nil {
+		t.Fatalf("ошибка %v", err)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	query := func(s string) url.Values {
+		t.Helper()
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.Query()
+	}
+	q := query(NewIssRequest().Stock().OnlyMarketData().Columns("marketdata", "SECID", "LAST").Columns("securities", "SECID").URL())
+	if got := q.Get("marketdata.columns"); got != "SECID,LAST" {
+		t.Errorf("marketdata.columns = %q", got)
+	}
+	if got := q.Get("securities.columns"); got != "SECID" {
+		t.Errorf("securities.columns = %q", got)
+	}
+	// повторный вызов для блока заменяет список
+	q = query(NewIssRequest().Stock().Columns("marketdata", "SECID").Columns("marketdata", "LAST").URL())
+	if got := q["marketdata.columns"]; len(got) != 1 || got[0] != "LAST" {
+		t.Errorf("marketdata.columns = %v", got)
+	}
+
+	type row struct {
+		SecID   string  `json:"SECID"`
+		Last    float64 `json:"LAST,omitempty"`
+		Skip    string  `json:"-"`
+		NoTag   string
+		private int
+	}
+	want := []string{"SECID", "LAST"}
+	for _, v := range []interface{}{row{}, &row{}, []row{}, []*row{}} {
+		if got := StructColumns(v); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("StructColumns(%T) = %v", v, got)
+		}
+	}
+	if got := StructColumns(1); got != nil {
+		t.Errorf("StructColumns(int) = %v", got)
+	}
+	if got := query(NewIssRequest().ColumnsOf("marketdata", []row{}).URL()).Get("marketdata.columns"); got != "SECID,LAST" {
+		t.Errorf("ColumnsOf: %q", got)
+	}
+}
+
+// рыночные данные запрашивают только колонки структуры
+func TestGetDataColumns(t *testing.T) {
+	f := newFakeISS()
+	client := newFakeClient(t, f)
+	tests := []struct {
+		name string
+		get  func() error
+		cols []string
+	}{
+		{"GetStockData", func() error { _, err := client.GetStockData("SBER"); return err }, StructColumns(StockData{})},
+		{"GetFortsData", func() error { _, err := client.GetFortsData("SiU4"); return err }, StructColumns(FortsData{})},
+		{"GetOptionData", func() error { _, err := client.GetOptionData("Si92500BI4"); return err }, StructColumns(OptionData{})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.mu.Lock()
+			f.requests = nil
+			f.mu.Unlock()
+			_ = tt.get()
+			if len(f.requests) != 1 {
+				t.Fatalf("запросы %v", f.requests)
+			}
+			q, _ := url.ParseQuery(strings.SplitN(f.requests[0], "?", 2)[1])
+			if got := q.Get("marketdata.columns"); got != strings.Join(tt.cols, ",") {
+				t.Errorf("marketdata.columns = %q", got)
+			}
+			if q.Get("iss.only") != "marketdata" || !slices.Contains(tt.cols, "SECID") {
+				t.Errorf("параметры %v", q)
+			}
+		})
+	}
+}
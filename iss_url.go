@@ -0,0 +1,151 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrIssURL не удалось разобрать строку url запроса iss
+var ErrIssURL = errors.New("не верная структура url запроса iss")
+
+// ParseIssURL разберем строку url (например скопированную из браузера) обратно в IssRequest
+// обратная операция к IssRequest.URL()
+//
+// https://iss.moex.com/iss/engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json?from=2024-08-01&interval=24
+// https://iss.moex.com/iss/history/engines/futures/markets/options/securities/Si88000BH4E.json
+// https://iss.moex.com/iss/datashop/algopack/eq/tradestats/SBER.json?from=2024-08-30
+func ParseIssURL(rawURL string) (*IssRequest, error) {
+	_url, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIssURL, err)
+	}
+	base, _ := url.Parse(DefaultApiURL)
+	if _url.Host != "" && _url.Host != base.Host {
+		return nil, fmt.Errorf("%w: host %s", ErrIssURL, _url.Host)
+	}
+	rest, ok := strings.CutPrefix(_url.Path, base.Path)
+	if !ok {
+		return nil, fmt.Errorf("%w: path %s", ErrIssURL, _url.Path)
+	}
+
+	u := NewIssRequest()
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+
+	// последний сегмент = target.format
+	last := segments[len(segments)-1]
+	segments = segments[:len(segments)-1]
+	ext := path.Ext(last)
+	switch ext {
+	case "." + FormatJson, "." + FormatXml, "." + FormatCsv:
+		u.format = strings.TrimPrefix(ext, ".")
+	default:
+		return nil, fmt.Errorf("%w: формат %s", ErrIssURL, last)
+	}
+	u.target = strings.TrimSuffix(last, ext)
+
+	// разберем остальные сегменты пути
+	next := func(i int, name string) (string, error) {
+		if i+1 >= len(segments) || segments[i+1] == "" {
+			return "", fmt.Errorf("%w: после %s нет значения", ErrIssURL, name)
+		}
+		return segments[i+1], nil
+	}
+	for i := 0; i < len(segments); i++ {
+		switch segment := segments[i]; segment {
+		case "datashop":
+			// datashop/algopack/<рынок>/<что выбираем>
+			if i+1 >= len(segments) || segments[i+1] != "algopack" {
+				return nil, fmt.Errorf("%w: ожидается datashop/algopack", ErrIssURL)
+			}
+			i++
+			if u.algoPackMarkets, err = next(i, "algopack"); err != nil {
+				return nil, err
+			}
+			i++
+			if i+1 < len(segments) {
+				u.algoPack = segments[i+1]
+				i++
+			} else {
+				// все данные за дату: datashop/algopack/eq/tradestats.json
+				u.algoPack = u.target
+				u.target = ""
+			}
+		case "history":
+			u.history = true
+		case "engines":
+			if u.engines, err = next(i, segment); err != nil {
+				return nil, err
+			}
+			i++
+		case "markets":
+			if u.markets, err = next(i, segment); err != nil {
+				return nil, err
+			}
+			i++
+		case "boards":
+			if u.boards, err = next(i, segment); err != nil {
+				return nil, err
+			}
+			i++
+		case "securities":
+			u.securities = true
+			// securities/<symbol>/candles.json
+			if i+1 < len(segments) {
+				u.symbol = segments[i+1]
+				i++
+			}
+		default:
+			return nil, fmt.Errorf("%w: сегмент %s", ErrIssURL, segment)
+		}
+	}
+	// securities/SECID.json = последний сегмент это код инструмента (history, описание инструмента)
+	if len(segments) > 0 && segments[len(segments)-1] == "securities" && u.algoPack == "" {
+		u.symbol = u.target
+		u.target = ""
+	}
+
+	// разберем параметры запроса
+	for key, values := range _url.Query() {
+		value := values[0]
+		switch {
+		case key == "iss.meta":
+			u.metadata = value != "off"
+		case key == "iss.only":
+			u.iss_only = value
+		case key == "iss.json":
+			u.jsonFull = value == "extended"
+		case key == "date":
+			u.date = value
+		case key == "from":
+			u.dateFrom = value
+		case key == "till":
+			u.dateTo = value
+		case key == "interval":
+			interval, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: interval=%s", ErrIssURL, value)
+			}
+			u.interval = Interval(interval)
+		case key == "start":
+			if u.start, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("%w: start=%s", ErrIssURL, value)
+			}
+		case key == "securities":
+			u.symbols = value
+		case key == "q":
+			u.q = value
+		case key == "latest":
+			u.latest = value == "1"
+		case strings.HasSuffix(key, ".columns"):
+			u = u.Columns(strings.TrimSuffix(key, ".columns"), strings.Split(value, ",")...)
+		default:
+			// остальные параметры (сортировка, фильтры) сохраним как есть
+			u = u.Param(key, value)
+		}
+	}
+	return u, nil
+}
@@ -0,0 +1,88 @@
+package iss
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseIssURLRoundTrip(t *testing.T) {
+	tests := []struct {
+		url    string
+		check  func(u *IssRequest) bool
+		detail string
+	}{
+		{
+			url: "https://iss.moex.com/iss/engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json?from=2024-08-01&interval=24",
+			check: func(u *IssRequest) bool {
+				return u.symbol == "SBER" && u.target == "candles" && u.interval == Interval_D1
+			},
+			detail: "свечи",
+		},
+		{
+			url:    "https://iss.moex.com/iss/history/engines/futures/markets/options/securities/Si88000BH4E.json",
+			check:  func(u *IssRequest) bool { return u.history && u.symbol == "Si88000BH4E" && u.target == "" },
+			detail: "history по инструменту",
+		},
+		{
+			url: "https://iss.moex.com/iss/datashop/algopack/eq/tradestats/SBER.json?from=2024-08-30",
+			check: func(u *IssRequest) bool {
+				return u.algoPack == "tradestats" && u.algoPackMarkets == "eq" && u.target == "SBER"
+			},
+			detail: "алгопак по инструменту",
+		},
+		{
+			url:    "https://iss.moex.com/iss/datashop/algopack/eq/tradestats.json?date=2024-08-30&latest=1",
+			check:  func(u *IssRequest) bool { return u.algoPack == "tradestats" && u.target == "" && u.latest },
+			detail: "алгопак все за дату",
+		},
+		{
+			url: "https://iss.moex.com/iss/history/engines/stock/markets/shares/boards/TQBR/securities.csv?date=2024-08-30&iss.meta=off&start=100",
+			check: func(u *IssRequest) bool {
+				return u.history && u.target == "securities" && u.format == FormatCsv && u.start == 100
+			},
+			detail: "history по всем инструментам режима",
+		},
+		{
+			url:    "https://iss.moex.com/iss/securities/SBER.json?iss.only=description",
+			check:  func(u *IssRequest) bool { return u.securities && u.symbol == "SBER" && u.iss_only == "description" },
+			detail: "описание инструмента",
+		},
+		{
+			url:    "https://iss.moex.com/iss/engines/stock/markets/shares/securities.xml?marketdata.columns=SECID%2CLAST&sort_column=VALTODAY&sort_order=desc",
+			check:  func(u *IssRequest) bool { return u.format == FormatXml && len(u.columns["marketdata"]) == 2 },
+			detail: "колонки и сортировка",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.detail, func(t *testing.T) {
+			u, err := ParseIssURL(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(u) {
+				t.Fatalf("не верно разобран запрос: %+v", u)
+			}
+			if got := u.URL(); got != tt.url {
+				t.Fatalf("URL()\n%s\nожидали\n%s", got, tt.url)
+			}
+			if err = u.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseIssURLErrors(t *testing.T) {
+	for _, rawURL := range []string{
+		"https://example.com/iss/securities.json",
+		"https://iss.moex.com/api/securities.json",
+		"https://iss.moex.com/iss/securities.html",
+		"https://iss.moex.com/iss/engines.json/x",
+		"https://iss.moex.com/iss/engines/stock/foo/shares.json",
+		"https://iss.moex.com/iss/engines/stock/markets/shares/securities/SBER/candles.json?interval=x",
+	} {
+		if _, err := ParseIssURL(rawURL); !errors.Is(err, ErrIssURL) {
+			t.Errorf("%s: ошибка %v, ожидали ErrIssURL", rawURL, err)
+		}
+	}
+}
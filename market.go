@@ -0,0 +1,91 @@
+package iss
+
+import (
+	"strings"
+	"time"
+)
+
+// Market рынок iss: торговая система (engine), рынок (market) и режим торгов (board) по умолчанию
+type Market struct {
+	Engine string
+	Market string
+	Board  string
+}
+
+// Рынки с режимами торгов по умолчанию.
+var (
+	MarketShares   = Market{Engine: "stock", Market: "shares", Board: StockBoard}      // акции TQBR
+	MarketETF      = Market{Engine: "stock", Market: "shares", Board: ETFBoard}        // фонды TQTF
+	MarketOFZ      = Market{Engine: "stock", Market: "bonds", Board: OFZBoard}         // ОФЗ TQOB
+	MarketBonds    = Market{Engine: "stock", Market: "bonds", Board: BondsBoard}       // корпоративные облигации TQCB
+	MarketIndex    = Market{Engine: "stock", Market: "index", Board: IndexBoard}       // индексы SNDX (IMOEX, RGBI)
+	MarketCurrency = Market{Engine: "currency", Market: "selt", Board: CurrencyBoard}  // валюта CETS
+	MarketFutures  = Market{Engine: "futures", Market: "forts", Board: FortsBoard}     // фьючерсы RFUD
+	MarketOptions  = Market{Engine: "futures", Market: "options", Board: OptionsBoard} // опционы ROPD
+)
+
+// WithBoard тот же рынок с другим режимом торгов
+func (m Market) WithBoard(board string) Market {
+	m.Board = board
+	return m
+}
+
+// Request запрос iss с параметрами рынка: /engines/(engine)/markets/(market)/boards/(board)
+func (m Market) Request() *IssRequest {
+	return NewIssRequest().Engines(m.Engine).Markets(m.Market).Boards(m.Board)
+}
+
+// String engine/market/board
+func (m Market) String() string {
+	return m.Engine + "/" + m.Market + "/" + m.Board
+}
+
+// BondMarket рынок облигации по коду: ОФЗ (код начинается с SU) = TQOB, остальные = TQCB
+func BondMarket(symbol string) Market {
+	if strings.HasPrefix(strings.ToUpper(symbol), "SU") {
+		return MarketOFZ
+	}
+	return MarketBonds
+}
+
+// NewMarketCandlesService сервис свечей по инструменту заданного рынка
+func (c *Client) NewMarketCandlesService(m Market, symbol string, interval Interval) *CandlesService {
+	return c.NewCandlesService(m.Engine, m.Market, m.Board, symbol, interval, "", "")
+}
+
+// GetMarketCandles получить историю свечей по инструменту заданного рынка
+func (c *Client) GetMarketCandles(m Market, symbol string, interval Interval, from, to time.Time) (Candles, error) {
+	s := c.NewMarketCandlesService(m, symbol, interval)
+	s.issRequest = s.issRequest.DateRange(from, to)
+	return s.Do()
+}
+
+// GetBondCandles получить историю свечей по облигации
+// Board TQOB (ОФЗ) или TQCB
+func (c *Client) GetBondCandles(symbol string, interval Interval, from, to time.Time) (Candles, error) {
+	return c.GetMarketCandles(BondMarket(symbol), symbol, interval, from, to)
+}
+
+// GetCurrencyCandles получить историю свечей по валюте (например CNYRUB_TOM)
+// Board CETS
+func (c *Client) GetCurrencyCandles(symbol string, interval Interval, from, to time.Time) (Candles, error) {
+	return c.GetMarketCandles(MarketCurrency, symbol, interval, from, to)
+}
+
+// GetIndexCandles получить историю свечей по индексу (например IMOEX, RGBI)
+// Board SNDX
+func (c *Client) GetIndexCandles(symbol string, interval Interval, from, to time.Time) (Candles, error) {
+	return c.GetMarketCandles(MarketIndex, symbol, interval, from, to)
+}
+
+// GetETFCandles получить историю свечей по фонду
+// Board TQTF
+func (c *Client) GetETFCandles(symbol string, interval Interval, from, to time.Time) (Candles, error) {
+	return c.GetMarketCandles(MarketETF, symbol, interval, from, to)
+}
+
+// GetOptionCandles получить историю свечей по опциону
+// Board ROPD
+func (c *Client) GetOptionCandles(symbol string, interval Interval, from, to time.Time) (Candles, error) {
+	return c.GetMarketCandles(MarketOptions, symbol, interval, from, to)
+}
@@ -0,0 +1,88 @@
+package iss
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBondMarket(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   Market
+	}{
+		{"SU26238RMFS4", MarketOFZ},
+		{"su26238rmfs4", MarketOFZ},
+		{"RU000A105KU0", MarketBonds},
+		{"XS0191754729", MarketBonds},
+		{"", MarketBonds},
+	}
+	for _, tt := range tests {
+		if got := BondMarket(tt.symbol); got != tt.want {
+			t.Errorf("BondMarket(%q) = %s, ожидали %s", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+// свечи по рынкам: engine, market и board по умолчанию в пути запроса
+func TestMarketCandlesURL(t *testing.T) {
+	from := time.Date(2024, 8, 1, 0, 0, 0, 0, TzMsk)
+	to := time.Date(2024, 8, 2, 0, 0, 0, 0, TzMsk)
+	tests := []struct {
+		name string
+		get  func(c *Client) (Candles, error)
+		path string
+	}{
+		{"GetBondCandles ОФЗ", func(c *Client) (Candles, error) {
+			return c.GetBondCandles("SU26238RMFS4", Interval_D1, from, to)
+		}, "engines/stock/markets/bonds/boards/TQOB/securities/SU26238RMFS4/candles.json"},
+		{"GetBondCandles корпоративная", func(c *Client) (Candles, error) {
+			return c.GetBondCandles("RU000A105KU0", Interval_D1, from, to)
+		}, "engines/stock/markets/bonds/boards/TQCB/securities/RU000A105KU0/candles.json"},
+		{"GetCurrencyCandles", func(c *Client) (Candles, error) {
+			return c.GetCurrencyCandles("CNYRUB_TOM", Interval_D1, from, to)
+		}, "engines/currency/markets/selt/boards/CETS/securities/CNYRUB_TOM/candles.json"},
+		{"GetIndexCandles", func(c *Client) (Candles, error) {
+			return c.GetIndexCandles("IMOEX", Interval_D1, from, to)
+		}, "engines/stock/markets/index/boards/SNDX/securities/IMOEX/candles.json"},
+		{"GetETFCandles", func(c *Client) (Candles, error) {
+			return c.GetETFCandles("TMOS", Interval_D1, from, to)
+		}, "engines/stock/markets/shares/boards/TQTF/securities/TMOS/candles.json"},
+		{"GetOptionCandles", func(c *Client) (Candles, error) {
+			return c.GetOptionCandles("Si92500BI4", Interval_D1, from, to)
+		}, "engines/futures/markets/options/boards/ROPD/securities/Si92500BI4/candles.json"},
+		{"GetMarketCandles фьючерс", func(c *Client) (Candles, error) {
+			return c.GetMarketCandles(MarketFutures, "SiU4", Interval_D1, from, to)
+		}, "engines/futures/markets/forts/boards/RFUD/securities/SiU4/candles.json"},
+		{"GetMarketCandles WithBoard", func(c *Client) (Candles, error) {
+			return c.GetMarketCandles(MarketShares.WithBoard("SMAL"), "SBER", Interval_D1, from, to)
+		}, "engines/stock/markets/shares/boards/SMAL/securities/SBER/candles.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeISS()
+			// одна свеча на первой странице
+			f.handle(tt.path, func(q url.Values) string {
+				if q.Get("start") != "" && q.Get("start") != "0" {
+					return issJSON(map[string]Table{"candles": table(candleColumns)})
+				}
+				return issJSON(map[string]Table{"candles": table(candleColumns,
+					[]interface{}{1.0, 2.0, 3.0, 0.5, 1e6, 1e3, "2024-08-01 00:00:00", "2024-08-01 23:59:59"})})
+			})
+			candles, err := tt.get(newFakeClient(t, f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(candles.Data) != 1 {
+				t.Fatalf("свечей %d, запросы %v", len(candles.Data), f.requests)
+			}
+			q, ok := f.last(tt.path)
+			if !ok {
+				t.Fatalf("запросы %v", f.requests)
+			}
+			if q.Get("from") != "2024-08-01" || q.Get("till") != "2024-08-02" || q.Get("interval") != "24" {
+				t.Errorf("параметры %v", q)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // OptionInfo параметры инструментов по опционам
@@ -22,7 +24,7 @@ type OptionInfo struct {
 	CentralStrike         float64 `json:"CENTRALSTRIKE" csv:"CENTRALSTRIKE"`                 // Центральный страйк
 	PrevSettlePrice       float64 `json:"PREVSETTLEPRICE" csv:"PREVSETTLEPRICE"`             // Расчетная цена предыдущего дня, рублей
 	Decimals              int     `json:"DECIMALS" csv:"DECIMALS"`                           // Точность
-	MinStep               float64 `json:"MINSTEP" csv:""MINSTEP"`                            // Мин. шаг цены
+	MinStep               float64 `json:"MINSTEP" csv:"MINSTEP"`                             // Мин. шаг цены
 	LastTradeDate         string  `json:"LASTTRADEDATE" csv:"LASTTRADEDATE"`                 // Последний торговый день
 	LastDelDate           string  `json:"LASTDELDATE" csv:"LASTDELDATE"`                     // День исполнения
 	PrevPrice             float64 `json:"PREVPRICE" csv:"PREVPRICE"`                         // Цена последней сделки предыдущего торгового дня
@@ -36,7 +38,7 @@ type OptionInfo struct {
 	ScalperFee            float64 `json:"SCALPERFEE" csv:"SCALPERFEE"`                       // Сбор за скальперскую сделку
 	NegotiatedFee         float64 `json:"NEGOTIATEDFEE" csv:"NEGOTIATEDFEE"`                 // Сбор за адресную сделку
 	ExerciseFee           float64 `json:"EXERCISEFEE" csv:"EXERCISEFEE"`                     // Клиринговая комиссия за исполнение контракта
-	AssetCode             string  `json:"ASSETCODE" csv:ASSETCODE"`                          // Код базового актива
+	AssetCode             string  `json:"ASSETCODE" csv:"ASSETCODE"`                         // Код базового актива
 	UnderlyingAsset       string  `json:"UNDERLYINGASSET" csv:"UNDERLYINGASSET"`             // Базовый актив
 	UnderlyingType        string  `json:"UNDERLYINGTYPE" csv:"UNDERLYINGTYPE"`               // Тип базового актива (F - фьючерс, S - акции)
 	UnderlyingSettlePrice float64 `json:"UNDERLYINGSETTLEPRICE" csv:"UNDERLYINGSETTLEPRICE"` // Котировка базового актива
@@ -110,9 +112,9 @@ func (c *Client) GetOptionInfo(symbols string) ([]OptionInfo, error) {
 	}
 
 	var resp Response
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -131,15 +133,15 @@ func (c *Client) GetOptionData(symbols string) ([]OptionData, error) {
 	var err error
 	const op = "GetOptionMarketData"
 
-	url := NewIssRequest().Options().Json().MetaData(false).OnlyMarketData().Symbols(symbols).URL()
+	url := NewIssRequest().Options().Json().MetaData(false).OnlyMarketData().ColumnsOf("marketdata", OptionData{}).Symbols(symbols).URL()
 	r := &request{
 		method:  http.MethodGet,
 		fullURL: url,
 	}
 	var resp Response
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -154,19 +156,26 @@ func (c *Client) GetOptionData(symbols string) ([]OptionData, error) {
 }
 
 // GetOptionHistory получить исторические данные по одному символу
-func (c *Client) GetOptionHistory(symbols string, from, to string) ([]OptionHistory, error) {
-	return c.NewOptionHistoryService(symbols, from, to, "").Do()
+func (c *Client) GetOptionHistory(symbols string, from, to time.Time) ([]OptionHistory, error) {
+	s := c.NewOptionHistoryService(symbols, "", "", "")
+	s.issRequest = s.issRequest.DateRange(from, to)
+	return s.Do()
 }
 
 // GetOptionHistoryAllDate получить исторические данные по всем символам за заданную дату
-func (c *Client) GetOptionHistoryAllDate(date string) ([]OptionHistory, error) {
-	return c.NewOptionHistoryService("", "", "", date).Do()
+func (c *Client) GetOptionHistoryAllDate(date time.Time) ([]OptionHistory, error) {
+	s := c.NewOptionHistoryService("", "", "", "")
+	s.issRequest = s.issRequest.OnDate(date)
+	return s.Do()
 }
 
 // OptionHistoryService сервис для получения исторических данных
 type OptionHistoryService struct {
 	client     *Client
-	issRequest *IssRequest
+	issRequest *IssRequest  // не изменяемый
+	progress   ProgressFunc // прогресс выгрузки
+	mu         sync.Mutex
+	state      pageState // состояние постраничной выгрузки (последний блок history.cursor)
 }
 
 // параметры должны быть
@@ -181,17 +190,63 @@ func (c *Client) NewOptionHistoryService(symbol string, from, to string, date st
 	}
 }
 
+// Csv запрашивать данные в формате csv (ответ меньше по размеру чем json)
+func (s *OptionHistoryService) Csv() *OptionHistoryService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issRequest = s.issRequest.Csv()
+	return s
+}
+
+// Progress установим функцию обратного вызова для отображения прогресса выгрузки
+func (s *OptionHistoryService) Progress(fn ProgressFunc) *OptionHistoryService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress = fn
+	return s
+}
+
+// Cursor вернем последний полученный курсор (false = если сервер его еще не прислал)
+func (s *OptionHistoryService) Cursor() (Cursor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.cursor == nil {
+		return Cursor{}, false
+	}
+	return *s.state.cursor, true
+}
+
+// Reset начать выгрузку заново
+func (s *OptionHistoryService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = pageState{}
+}
+
+// Clone независимая копия сервиса с начальным состоянием выгрузки
+func (s *OptionHistoryService) Clone() *OptionHistoryService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &OptionHistoryService{
+		client:     s.client,
+		issRequest: s.issRequest,
+		progress:   s.progress,
+	}
+}
+
 // Do выполняет выгрузку History
 func (s *OptionHistoryService) Do() ([]OptionHistory, error) {
 	const op = "OptionHistoryService.Do"
 
+	// отдельная копия = Do не мешает вызовам Next и другим Do
+	it := s.Clone()
 	result := make([]OptionHistory, 0)
 	count := 1
 	for {
 		// "fetch candles: item(s) processed"
 		s.client.log.Debug(op, "запрос истории: номер запроса", count)
 
-		t_result, err := s.Next()
+		t_result, err := it.Next()
 		if err != nil {
 			if errors.Is(err, EOF) {
 				break
@@ -210,15 +265,35 @@ func (s *OptionHistoryService) Next() ([]OptionHistory, error) {
 	var err error
 	const op = "OptionHistoryService.Next"
 
+	// прогресс сообщаем после s.mu.Unlock (defer выполняются в обратном порядке):
+	// из ProgressFunc можно вызывать методы сервиса
+	var notify func()
+	defer func() {
+		if notify != nil {
+			notify()
+		}
+	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.issRequest.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// по курсору видно что данных больше нет = запрос не делаем
+	if s.state.done() {
+		return nil, EOF
+	}
+
 	r := &request{
 		method:  http.MethodGet,
-		fullURL: s.issRequest.URL(),
+		fullURL: s.issRequest.Start(s.state.start).URL(),
 	}
 
 	var resp Response
-	err = s.client.getJSON(r, &resp)
+	err = s.client.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -234,9 +309,13 @@ func (s *OptionHistoryService) Next() ([]OptionHistory, error) {
 	}
 	s.client.log.Debug(op, "len(result)", len(result))
 
-	// увеличим параметр start на кол-во полученных данных
-	s.issRequest.start += len(result)
-	s.client.log.Debug(op, "s.issRequest.start", s.issRequest.start)
+	cursor, ok := parseCursor(resp.HistoryCursor.Columns, resp.HistoryCursor.Data)
+	s.state.update(len(result), cursor, ok)
+	s.client.log.Debug(op, "start", s.state.start)
+	if progress := s.progress; progress != nil {
+		fetched, total := s.state.fetched, s.state.total()
+		notify = func() { progress(fetched, total) }
+	}
 
 	return result, nil
 }
@@ -209,9 +209,9 @@ func (s OrderBookService) Do() (OrderBook, error) {
 	}
 
 	var resp Response
-	err = s.client.getJSON(r, &resp)
+	err = s.client.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return result, fmt.Errorf("%s: %w", op, err)
 	}
 
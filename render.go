@@ -12,32 +12,42 @@ const DefaultTagKey string = "json"
 //const DefaultTagKey string = "csv"
 
 // Иногда приходят null значение
+// в форматах xml и csv все значения приходят строкой
 func parseStringWithDefaultValue(fieldValue interface{}) string {
-	if fieldValue == nil {
+	switch v := fieldValue.(type) {
+	case nil:
 		return ""
+	case string:
+		return v
 	}
-	return fieldValue.(string)
+	s, _ := toString(fieldValue)
+	return s
 }
 
 // setFloatField
 func parseFloatWithDefaultValue(fieldValue interface{}) float64 {
-	if fieldValue == nil {
+	switch v := fieldValue.(type) {
+	case nil:
 		return 0
+	case float64:
+		return v
 	}
-	return fieldValue.(float64)
+	f, _ := toFloat(fieldValue)
+	return f
 }
 
 func parseIntWithDefaultValue(fieldValue interface{}) int {
-	if fieldValue == nil {
-		return 0
-	}
-	return int(fieldValue.(float64))
+	return int(parseInt64WithDefaultValue(fieldValue))
 }
 func parseInt64WithDefaultValue(fieldValue interface{}) int64 {
-	if fieldValue == nil {
+	switch v := fieldValue.(type) {
+	case nil:
 		return 0
+	case float64:
+		return int64(v)
 	}
-	return int64(fieldValue.(float64))
+	i, _ := toInt(fieldValue)
+	return i
 }
 
 // Unmarshal парсинг массивов. По аналогии с csv
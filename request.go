@@ -1,6 +1,7 @@
 package iss
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,7 +20,8 @@ type request struct {
 	body              io.Reader
 	fullURL           string
 	baseURL           string
-	authorizationOnly bool // Запрос нужно делать ТОЛЬКО с авторизацией
+	authorizationOnly bool            // Запрос нужно делать ТОЛЬКО с авторизацией
+	ctx               context.Context // контекст запроса (nil = context.Background)
 	//notAuthorization bool // Запрос нужно делать быть без авторизации
 
 }
@@ -0,0 +1,203 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrResample свечи нельзя пересчитать в заданный таймфрейм
+var ErrResample = errors.New("не возможно пересчитать свечи в заданный таймфрейм")
+
+// Session торговая сессия: смещение начала и конца от полуночи по московскому времени
+// внутридневные свечи выравниваются по началу сессии и не переходят через ее конец
+type Session struct {
+	Name  string
+	Start time.Duration
+	End   time.Duration
+}
+
+// Contains время суток (смещение от полуночи) попадает в сессию
+func (s Session) Contains(offset time.Duration) bool {
+	return offset >= s.Start && offset < s.End
+}
+
+// DefaultSessions сессии по умолчанию (фондовый и срочный рынок)
+// утренняя 07:00-10:00, основная 10:00-19:00, вечерняя 19:00-24:00
+var DefaultSessions = []Session{
+	{Name: "morning", Start: 7 * time.Hour, End: 10 * time.Hour},
+	{Name: "main", Start: 10 * time.Hour, End: 19 * time.Hour},
+	{Name: "evening", Start: 19 * time.Hour, End: 24 * time.Hour},
+}
+
+// Timeframe таймфрейм для пересчета свечей
+// задается одно из:
+// Duration = внутридневная свеча произвольной длины (M5 M15 M30 H4 ...)
+// Period = календарный период D1 W1 MN1 Q1
+// Session = одна свеча на торговую сессию
+type Timeframe struct {
+	Name     string
+	Duration time.Duration
+	Period   Interval
+	Session  bool
+}
+
+// Часто используемые таймфреймы, которых нет в iss.
+var (
+	Timeframe_M5      = NewTimeframe(5 * time.Minute)
+	Timeframe_M15     = NewTimeframe(15 * time.Minute)
+	Timeframe_M30     = NewTimeframe(30 * time.Minute)
+	Timeframe_H4      = NewTimeframe(4 * time.Hour)
+	Timeframe_Session = Timeframe{Name: "SESSION", Session: true}
+)
+
+// NewTimeframe внутридневной таймфрейм заданной длины
+// название: M5, H4 (если длина кратна минуте или часу)
+func NewTimeframe(d time.Duration) Timeframe {
+	name := d.String()
+	switch {
+	case d > 0 && d%time.Hour == 0:
+		name = fmt.Sprintf("H%d", d/time.Hour)
+	case d > 0 && d%time.Minute == 0:
+		name = fmt.Sprintf("M%d", d/time.Minute)
+	}
+	return Timeframe{Name: name, Duration: d}
+}
+
+// TimeframeOf таймфрейм, совпадающий с интервалом iss
+func TimeframeOf(i Interval) Timeframe {
+	switch i {
+	case Interval_M1, Interval_M10, Interval_H1:
+		return Timeframe{Name: i.String(), Duration: i.Duration()}
+	}
+	return Timeframe{Name: i.String(), Period: i}
+}
+
+// String название таймфрейма
+func (tf Timeframe) String() string {
+	return tf.Name
+}
+
+// validate таймфрейм задан правильно
+func (tf Timeframe) validate() error {
+	switch {
+	case tf.Session:
+		return nil
+	case tf.Duration > 0:
+		if tf.Duration%time.Minute != 0 || tf.Duration > 24*time.Hour {
+			return fmt.Errorf("%w: %s", ErrResample, tf.Duration)
+		}
+		return nil
+	case tf.Period == Interval_D1, tf.Period == Interval_W1, tf.Period == Interval_MN1, tf.Period == Interval_Q1:
+		return nil
+	}
+	return fmt.Errorf("%w: не задан таймфрейм %q", ErrResample, tf.Name)
+}
+
+// BaseInterval самый крупный интервал iss, из которого можно собрать таймфрейм
+// для внутридневных таймфреймов учитываются границы сессий (пустой список = DefaultSessions)
+func (tf Timeframe) BaseInterval(sessions ...Session) (Interval, error) {
+	if err := tf.validate(); err != nil {
+		return 0, err
+	}
+	if !tf.Session && tf.Duration == 0 {
+		return tf.Period, nil
+	}
+	if len(sessions) == 0 {
+		sessions = DefaultSessions
+	}
+	for _, base := range []Interval{Interval_H1, Interval_M10, Interval_M1} {
+		d := base.Duration()
+		if !tf.Session && tf.Duration%d != 0 {
+			continue
+		}
+		aligned := true
+		for _, s := range sessions {
+			if s.Start%d != 0 || s.End%d != 0 {
+				aligned = false
+				break
+			}
+		}
+		if aligned {
+			return base, nil
+		}
+	}
+	return Interval_M1, nil
+}
+
+// bucket время начала свечи таймфрейма, в которую попадает t
+func (tf Timeframe) bucket(t time.Time, sessions []Session) time.Time {
+	if !tf.Session && tf.Duration == 0 {
+		return tf.Period.Truncate(t)
+	}
+	t = t.In(TzMsk)
+	year, month, day := t.Date()
+	dayStart := time.Date(year, month, day, 0, 0, 0, 0, TzMsk)
+	offset := t.Sub(dayStart)
+
+	// вне сессий выравниваем от полуночи
+	start := time.Duration(0)
+	for _, s := range sessions {
+		if s.Contains(offset) {
+			start = s.Start
+			break
+		}
+	}
+	if tf.Session {
+		return dayStart.Add(start)
+	}
+	// последняя свеча сессии может быть короче таймфрейма
+	return dayStart.Add(start + (offset - start).Truncate(tf.Duration))
+}
+
+// Resample пересчитать свечи в заданный таймфрейм
+// Open = первой свечи, Close = последней, High = max, Low = min, Volume и Value = сумма
+// время по московскому времени, внутридневные свечи выравниваются по началу сессии
+// пустой список сессий = DefaultSessions
+func (k Candles) Resample(target Timeframe, sessions ...Session) (Candles, error) {
+	const op = "Candles.Resample"
+	if err := target.validate(); err != nil {
+		return Candles{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(sessions) == 0 {
+		sessions = DefaultSessions
+	}
+	// исходный интервал должен укладываться в таймфрейм целое число раз
+	if source, err := ParseInterval(k.Interval); err == nil && target.Duration > 0 {
+		d := TimeframeOf(source).Duration
+		if d == 0 || target.Duration%d != 0 {
+			return Candles{}, fmt.Errorf("%s: %w: %s -> %s", op, ErrResample, source, target)
+		}
+	}
+
+	data := slices.Clone(k.Data)
+	slices.SortStableFunc(data, func(a, b Candle) int {
+		return a.Time().Compare(b.Time())
+	})
+
+	result := Candles{
+		Symbol:   k.Symbol,
+		Interval: target.Name,
+		Data:     make([]Candle, 0, len(data)),
+	}
+	var current time.Time
+	for _, candle := range data {
+		begin := target.bucket(candle.Time(), sessions)
+		n := len(result.Data)
+		if n == 0 || !begin.Equal(current) {
+			current = begin
+			candle.Begin = begin.Format(layout)
+			result.Data = append(result.Data, candle)
+			continue
+		}
+		last := &result.Data[n-1]
+		last.High = max(last.High, candle.High)
+		last.Low = min(last.Low, candle.Low)
+		last.Close = candle.Close
+		last.Volume += candle.Volume
+		last.Value += candle.Value
+		last.End = candle.End
+	}
+	return result, nil
+}
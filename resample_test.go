@@ -0,0 +1,164 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// hourCandles часовые свечи за день: open = час, close = час + 0.5, объем = 1
+func hourCandles(day string, hours ...int) Candles {
+	k := Candles{Symbol: "SBER", Interval: Interval_H1.String()}
+	for _, h := range hours {
+		k.Data = append(k.Data, Candle{
+			Open: float64(h), Close: float64(h) + 0.5, High: float64(h) + 1, Low: float64(h) - 1,
+			Volume: 1, Value: 100,
+			Begin: fmt.Sprintf("%s %02d:00:00", day, h),
+			End:   fmt.Sprintf("%s %02d:59:59", day, h),
+		})
+	}
+	return k
+}
+
+func TestCandleTimeMsk(t *testing.T) {
+	got := Candle{Begin: "2024-08-07 10:00:00"}.Time()
+	want := time.Date(2024, 8, 7, 7, 0, 0, 0, time.UTC)
+	if !got.Equal(want) || got.Location() != TzMsk {
+		t.Fatalf("Time() = %v, ожидали %v", got, want)
+	}
+}
+
+func TestResampleH4Sessions(t *testing.T) {
+	k := hourCandles("2024-08-07", 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20)
+	// перемешаем: Resample сортирует по времени
+	k.Data[0], k.Data[5] = k.Data[5], k.Data[0]
+
+	got, err := k.Resample(Timeframe_H4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// утренняя сессия 07:00, основная 10:00 14:00 18:00 (последняя свеча короче), вечерняя 19:00
+	want := []struct {
+		begin             string
+		open, close       float64
+		high, low, volume float64
+	}{
+		{"2024-08-07 07:00:00", 9, 9.5, 10, 8, 1},
+		{"2024-08-07 10:00:00", 10, 13.5, 14, 9, 4},
+		{"2024-08-07 14:00:00", 14, 17.5, 18, 13, 4},
+		{"2024-08-07 18:00:00", 18, 18.5, 19, 17, 1},
+		{"2024-08-07 19:00:00", 19, 20.5, 21, 18, 2},
+	}
+	if got.Interval != "H4" || len(got.Data) != len(want) {
+		t.Fatalf("%s: %d свечей %+v", got.Interval, len(got.Data), got.Data)
+	}
+	for i, w := range want {
+		c := got.Data[i]
+		if c.Begin != w.begin || c.Open != w.open || c.Close != w.close || c.High != w.high || c.Low != w.low || c.Volume != w.volume || c.Value != 100*w.volume {
+			t.Errorf("свеча %d = %+v, ожидали %+v", i, c, w)
+		}
+	}
+	if got.Data[1].End != "2024-08-07 13:59:59" {
+		t.Errorf("End = %s", got.Data[1].End)
+	}
+}
+
+func TestResampleSession(t *testing.T) {
+	k := hourCandles("2024-08-07", 9, 10, 18, 19, 23)
+	k = Candles{Symbol: k.Symbol, Interval: k.Interval, Data: append(k.Data, hourCandles("2024-08-08", 10).Data...)}
+	got, err := k.Resample(Timeframe_Session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2024-08-07 07:00:00", "2024-08-07 10:00:00", "2024-08-07 19:00:00", "2024-08-08 10:00:00"}
+	if len(got.Data) != len(want) {
+		t.Fatalf("%d свечей: %+v", len(got.Data), got.Data)
+	}
+	for i, begin := range want {
+		if got.Data[i].Begin != begin {
+			t.Errorf("свеча %d begin = %s, ожидали %s", i, got.Data[i].Begin, begin)
+		}
+	}
+	if got.Data[2].Volume != 2 {
+		t.Errorf("вечерняя сессия volume = %v", got.Data[2].Volume)
+	}
+
+	// свои сессии: одна сессия 10:00-18:45, все остальное выравнивается от полуночи
+	own := []Session{{Name: "main", Start: 10 * time.Hour, End: 18*time.Hour + 45*time.Minute}}
+	got, err = hourCandles("2024-08-07", 9, 10, 18, 19).Resample(Timeframe_Session, own...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Data) != 3 || got.Data[0].Begin != "2024-08-07 00:00:00" || got.Data[1].Begin != "2024-08-07 10:00:00" || got.Data[2].Begin != "2024-08-07 00:00:00" {
+		t.Fatalf("%+v", got.Data)
+	}
+}
+
+func TestResamplePeriod(t *testing.T) {
+	k := Candles{Interval: Interval_D1.String()}
+	for _, day := range []string{"2024-08-01", "2024-08-02", "2024-08-05", "2024-08-09", "2024-08-12", "2024-09-02"} {
+		k.Data = append(k.Data, Candle{Open: 1, Close: 2, High: 3, Low: 0, Volume: 10, Begin: day + " 00:00:00"})
+	}
+	tests := []struct {
+		tf    Timeframe
+		begin []string
+	}{
+		{TimeframeOf(Interval_W1), []string{"2024-07-29 00:00:00", "2024-08-05 00:00:00", "2024-08-12 00:00:00", "2024-09-02 00:00:00"}},
+		{TimeframeOf(Interval_MN1), []string{"2024-08-01 00:00:00", "2024-09-01 00:00:00"}},
+		{TimeframeOf(Interval_Q1), []string{"2024-07-01 00:00:00"}},
+	}
+	for _, tt := range tests {
+		got, err := k.Resample(tt.tf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Data) != len(tt.begin) {
+			t.Fatalf("%s: %+v", tt.tf, got.Data)
+		}
+		for i, begin := range tt.begin {
+			if got.Data[i].Begin != begin {
+				t.Errorf("%s: свеча %d begin = %s, ожидали %s", tt.tf, i, got.Data[i].Begin, begin)
+			}
+		}
+	}
+}
+
+func TestResampleErrors(t *testing.T) {
+	k := hourCandles("2024-08-07", 10, 11)
+	for _, tf := range []Timeframe{
+		NewTimeframe(30 * time.Minute), // H1 не укладывается в M30
+		NewTimeframe(90 * time.Minute), // H1 не укладывается в 1.5 часа
+		NewTimeframe(30 * time.Second), // меньше минуты
+		NewTimeframe(48 * time.Hour),   // больше суток
+		{Name: "empty"},
+	} {
+		if _, err := k.Resample(tf); !errors.Is(err, ErrResample) {
+			t.Errorf("%s: ошибка %v, ожидали ErrResample", tf, err)
+		}
+	}
+}
+
+func TestBaseInterval(t *testing.T) {
+	halfHour := []Session{{Name: "main", Start: 10 * time.Hour, End: 18*time.Hour + 50*time.Minute}}
+	odd := []Session{{Name: "main", Start: 10 * time.Hour, End: 18*time.Hour + 45*time.Minute}}
+	tests := []struct {
+		tf       Timeframe
+		sessions []Session
+		want     Interval
+	}{
+		{Timeframe_H4, nil, Interval_H1},
+		{Timeframe_M30, nil, Interval_M10},
+		{Timeframe_M5, nil, Interval_M1},
+		{Timeframe_H4, halfHour, Interval_M10},
+		{Timeframe_H4, odd, Interval_M1},
+		{Timeframe_Session, nil, Interval_H1},
+		{TimeframeOf(Interval_W1), nil, Interval_W1},
+	}
+	for _, tt := range tests {
+		got, err := tt.tf.BaseInterval(tt.sessions...)
+		if err != nil || got != tt.want {
+			t.Errorf("%s.BaseInterval(%v) = %v, %v, ожидали %v", tt.tf, tt.sessions, got, err, tt.want)
+		}
+	}
+}
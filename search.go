@@ -0,0 +1,95 @@
+package iss
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// https://iss.moex.com/iss/securities.json?q=сбер&engine=stock&market=shares&is_trading=1
+
+// Security инструмент из справочника /iss/securities (результат поиска)
+type Security struct {
+	ID                 int64  `json:"id"`
+	SecID              string `json:"secid"`
+	ShortName          string `json:"shortname"`
+	RegNumber          string `json:"regnumber"` // номер гос. регистрации
+	Name               string `json:"name"`
+	ISIN               string `json:"isin"`
+	IsTraded           int    `json:"is_traded"` // 1 = торгуется
+	EmitentID          int64  `json:"emitent_id"`
+	EmitentTitle       string `json:"emitent_title"`
+	EmitentINN         string `json:"emitent_inn"`
+	EmitentOKPO        string `json:"emitent_okpo"`
+	GosReg             string `json:"gosreg"`
+	Type               string `json:"type"`            // тип: common_share, ofz_bond, futures ...
+	Group              string `json:"group"`           // группа: stock_shares, stock_bonds, futures_forts ...
+	PrimaryBoardID     string `json:"primary_boardid"` // основной режим торгов
+	MarketPriceBoardID string `json:"marketprice_boardid"`
+}
+
+// SearchFilter фильтры поиска инструментов (пустое значение = без фильтра)
+type SearchFilter struct {
+	Engine    string // торговая система: stock, currency, futures
+	Market    string // рынок: shares, bonds, index, forts ...
+	Group     string // группа инструментов: stock_shares, stock_bonds, futures_forts ...
+	IsTrading bool   // только торгуемые
+	Start     int    // номер первой записи (постраничная выдача)
+	Limit     int    // записей на странице (не больше MaxLimit, 0 = по умолчанию сервера)
+}
+
+// request параметры фильтра в запросе
+func (f SearchFilter) request(query string) *IssRequest {
+	req := NewIssRequest().Target("securities").Query(query).Json().MetaData(false).Start(f.Start)
+	if f.Engine != "" {
+		req = req.Param("engine", f.Engine)
+	}
+	if f.Market != "" {
+		req = req.Param("market", f.Market)
+	}
+	if f.Group != "" {
+		req = req.Param("group_by", "group").Param("group_by_filter", f.Group)
+	}
+	if f.IsTrading {
+		req = req.Param("is_trading", 1)
+	}
+	if f.Limit != 0 {
+		req = req.Limit(f.Limit)
+	}
+	return req
+}
+
+// SearchSecurities поиск инструментов по части кода, названию, ISIN, идентификатору эмитента,
+// номеру гос. регистрации (query не короче MinQueryLen символов)
+// следующая страница: filter.Start += количество полученных записей
+// пустой результат = больше ничего не найдено
+func (c *Client) SearchSecurities(ctx context.Context, query string, filter SearchFilter) ([]Security, error) {
+	var err error
+	const op = "SearchSecurities"
+
+	req := filter.request(query)
+	if err = req.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	r := &request{
+		method:  http.MethodGet,
+		fullURL: req.URL(),
+		ctx:     ctx,
+	}
+
+	var resp Response
+	err = c.getData(r, &resp)
+	if err != nil {
+		slog.Error(op+".getData", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make([]Security, 0, len(resp.Securities.Data))
+	err = Unmarshal(resp.Securities.Columns, resp.Securities.Data, &result)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package iss
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+var securityColumns = []string{"id", "secid", "shortname", "isin", "is_traded", "type", "group", "primary_boardid"}
+
+// fakeSearch справочник /iss/securities.json: 5 облигаций Сбера, страницы по limit (по умолчанию 2)
+func fakeSearch(f *fakeISS) {
+	f.handle("securities.json", func(q url.Values) string {
+		start, _ := strconv.Atoi(q.Get("start"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		if limit == 0 {
+			limit = 2
+		}
+		rows := make([][]interface{}, 0)
+		for i := start; i < start+limit && i < 5; i++ {
+			secid := "RU000A10" + strconv.Itoa(i)
+			rows = append(rows, []interface{}{i + 1, secid, "Сбер Sb" + strconv.Itoa(i), secid, 1, "exchange_bond", "stock_bonds", "TQCB"})
+		}
+		return issJSON(map[string]Table{"securities": table(securityColumns, rows...)})
+	})
+}
+
+func TestSearchSecuritiesQuery(t *testing.T) {
+	f := newFakeISS()
+	fakeSearch(f)
+	client := newFakeClient(t, f)
+	ctx := context.Background()
+
+	// короче MinQueryLen = ошибка без запроса к серверу
+	for _, query := range []string{"Сб", "SB"} {
+		if _, err := client.SearchSecurities(ctx, query, SearchFilter{}); !errors.Is(err, ErrParam) {
+			t.Errorf("q=%s: %v", query, err)
+		}
+	}
+	if n := f.count("securities.json"); n != 0 {
+		t.Fatalf("запросов: %d", n)
+	}
+	// длина в символах, а не в байтах
+	if _, err := client.SearchSecurities(ctx, "Сбе", SearchFilter{}); err != nil {
+		t.Fatalf("q=Сбе: %v", err)
+	}
+	q, _ := f.last("securities.json")
+	if q.Get("q") != "Сбе" || q.Get("iss.meta") != "off" {
+		t.Fatalf("параметры %v", q)
+	}
+	// limit больше MaxLimit
+	if _, err := client.SearchSecurities(ctx, "сбер", SearchFilter{Limit: MaxLimit + 1}); !errors.Is(err, ErrParam) {
+		t.Fatalf("limit=%d: %v", MaxLimit+1, err)
+	}
+	// отмена контекста
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.SearchSecurities(cancelled, "сбер", SearchFilter{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("отмененный контекст: %v", err)
+	}
+}
+
+func TestSearchSecuritiesFilter(t *testing.T) {
+	f := newFakeISS()
+	fakeSearch(f)
+	client := newFakeClient(t, f)
+
+	tests := []struct {
+		name   string
+		filter SearchFilter
+		want   url.Values
+	}{
+		{"без фильтра", SearchFilter{}, url.Values{}},
+		{"рынок", SearchFilter{Engine: "stock", Market: "bonds"}, url.Values{"engine": {"stock"}, "market": {"bonds"}}},
+		{"группа", SearchFilter{Group: "stock_bonds"}, url.Values{"group_by": {"group"}, "group_by_filter": {"stock_bonds"}}},
+		{"торгуемые", SearchFilter{IsTrading: true}, url.Values{"is_trading": {"1"}}},
+		{"страница", SearchFilter{Start: 20, Limit: 10}, url.Values{"start": {"20"}, "limit": {"10"}}},
+	}
+	params := []string{"engine", "market", "group_by", "group_by_filter", "is_trading", "start", "limit"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.SearchSecurities(context.Background(), "сбер", tt.filter); err != nil {
+				t.Fatal(err)
+			}
+			q, _ := f.last("securities.json")
+			for _, key := range params {
+				if q.Get(key) != tt.want.Get(key) {
+					t.Errorf("%s=%q, ожидали %q", key, q.Get(key), tt.want.Get(key))
+				}
+			}
+		})
+	}
+}
+
+func TestSearchSecuritiesPaging(t *testing.T) {
+	f := newFakeISS()
+	fakeSearch(f)
+	client := newFakeClient(t, f)
+
+	// запросов = страниц с данными + последний пустой ответ
+	for limit, want := range map[int]int{0: 4, 2: 4, 3: 3, MaxLimit: 2} {
+		filter := SearchFilter{Limit: limit}
+		var all []Security
+		pages := 0
+		for {
+			page, err := client.SearchSecurities(context.Background(), "сбер", filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pages++
+			if len(page) == 0 {
+				break
+			}
+			all = append(all, page...)
+			filter.Start += len(page)
+		}
+		if len(all) != 5 {
+			t.Fatalf("limit=%d: найдено %d, ожидали 5", limit, len(all))
+		}
+		for i, s := range all {
+			if s.SecID != "RU000A10"+strconv.Itoa(i) || s.ID != int64(i+1) || s.Group != "stock_bonds" {
+				t.Fatalf("limit=%d: запись %d = %+v", limit, i, s)
+			}
+		}
+		if pages != want {
+			t.Errorf("limit=%d: %d запросов, ожидали %d", limit, pages, want)
+		}
+	}
+}
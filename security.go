@@ -0,0 +1,203 @@
+package iss
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// https://iss.moex.com/iss/securities/SBER.json?iss.only=boards
+
+// SecurityBoard режим торгов, на котором торгуется (торговался) инструмент
+type SecurityBoard struct {
+	SecID        string `json:"secid"`
+	BoardID      string `json:"boardid"`        // код режима торгов
+	Title        string `json:"title"`          // название режима торгов
+	BoardGroupID int    `json:"board_group_id"` // группа режимов
+	MarketID     int    `json:"market_id"`
+	Market       string `json:"market"` // рынок (shares, bonds, forts ...)
+	EngineID     int    `json:"engine_id"`
+	Engine       string `json:"engine"`       // торговая система (stock, currency, futures)
+	IsTraded     int    `json:"is_traded"`    // 1 = торгуется
+	Decimals     int    `json:"decimals"`     // точность цены
+	HistoryFrom  string `json:"history_from"` // начало истории итогов торгов
+	HistoryTill  string `json:"history_till"` // окончание истории итогов торгов
+	ListedFrom   string `json:"listed_from"`
+	ListedTill   string `json:"listed_till"`
+	IsPrimary    int    `json:"is_primary"` // 1 = основной режим торгов
+	CurrencyID   string `json:"currencyid"` // валюта расчетов
+}
+
+// MarketBoard рынок и режим торгов для запросов (GetMarketCandles ...)
+func (b SecurityBoard) MarketBoard() Market {
+	return Market{Engine: b.Engine, Market: b.Market, Board: b.BoardID}
+}
+
+// SecurityBoards режимы торгов инструмента
+type SecurityBoards []SecurityBoard
+
+// Select выберем режим торгов по фильтру (пустое значение = любое, регистр не важен)
+// из подходящих: основной режим (is_primary), затем торгуемый, затем первый
+func (b SecurityBoards) Select(engine, market, board string) (SecurityBoard, bool) {
+	var traded, first *SecurityBoard
+	for i := range b {
+		sb := &b[i]
+		if !matchFilter(engine, sb.Engine) || !matchFilter(market, sb.Market) || !matchFilter(board, sb.BoardID) {
+			continue
+		}
+		if sb.IsPrimary == 1 {
+			return *sb, true
+		}
+		if traded == nil && sb.IsTraded == 1 {
+			traded = sb
+		}
+		if first == nil {
+			first = sb
+		}
+	}
+	switch {
+	case traded != nil:
+		return *traded, true
+	case first != nil:
+		return *first, true
+	}
+	return SecurityBoard{}, false
+}
+
+// Primary основной режим торгов
+func (b SecurityBoards) Primary() (SecurityBoard, bool) {
+	return b.Select("", "", "")
+}
+
+// matchFilter пустой фильтр = подходит любое значение
+func matchFilter(filter, value string) bool {
+	return filter == "" || strings.EqualFold(filter, value)
+}
+
+// GetSecurityBoards получить режимы торгов инструмента по всем рынкам
+func (c *Client) GetSecurityBoards(secid string) (SecurityBoards, error) {
+	var err error
+	const op = "GetSecurityBoards"
+
+	r := &request{
+		method:  http.MethodGet,
+		fullURL: NewIssRequest().WithSecurities(true).Target(secid).Only("boards").Json().MetaData(false).URL(),
+	}
+
+	var resp Response
+	err = c.getData(r, &resp)
+	if err != nil {
+		slog.Error(op+".getData", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make(SecurityBoards, 0, len(resp.Boards.Data))
+	err = Unmarshal(resp.Boards.Columns, resp.Boards.Data, &result)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
+
+// DescriptionItem строка блока description: параметр инструмента
+type DescriptionItem struct {
+	Name      string `json:"name"`  // код параметра (SECID, ISIN, FACEVALUE ...)
+	Title     string `json:"title"` // название параметра
+	Value     string `json:"value"`
+	Type      string `json:"type"` // тип значения: string, number, date, boolean
+	SortOrder int    `json:"sort_order"`
+	IsHidden  int    `json:"is_hidden"`
+	Precision int    `json:"precision"`
+}
+
+// SecurityDescription описание инструмента (блок description) и режимы торгов (блок boards)
+// состав параметров зависит от типа инструмента: все строки есть в Items
+type SecurityDescription struct {
+	SecID                string  `json:"SECID"`
+	Name                 string  `json:"NAME"` // полное наименование
+	ShortName            string  `json:"SHORTNAME"`
+	LatName              string  `json:"LATNAME"`
+	ISIN                 string  `json:"ISIN"`
+	RegNumber            string  `json:"REGNUMBER"` // номер гос. регистрации
+	IssueSize            int64   `json:"ISSUESIZE"` // объем выпуска
+	FaceValue            float64 `json:"FACEVALUE"` // номинал
+	FaceUnit             string  `json:"FACEUNIT"`  // валюта номинала
+	IssueDate            string  `json:"ISSUEDATE"` // дата начала торгов
+	MatDate              string  `json:"MATDATE"`   // дата погашения (облигации)
+	ListLevel            int     `json:"LISTLEVEL"` // уровень листинга
+	IsQualifiedInvestors int     `json:"ISQUALIFIEDINVESTORS"`
+	Type                 string  `json:"TYPE"` // тип: common_share, ofz_bond ...
+	TypeName             string  `json:"TYPENAME"`
+	Group                string  `json:"GROUP"` // группа: stock_shares, stock_bonds ...
+	GroupName            string  `json:"GROUPNAME"`
+	EmitterID            int64   `json:"EMITTER_ID"`
+
+	Items  []DescriptionItem `json:"-"`
+	Boards SecurityBoards    `json:"-"`
+}
+
+// Get значение параметра по коду (ISIN, COUPONPERCENT ...)
+func (d SecurityDescription) Get(name string) (string, bool) {
+	for _, item := range d.Items {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+// GetSecurityDescription получить описание инструмента и режимы торгов по всем рынкам
+// состав параметров зависит от типа инструмента: отсутствующие, пустые и null значения = нулевые значения полей
+// (ошибка только если описания нет совсем = ErrTickerNotFound)
+func (c *Client) GetSecurityDescription(secid string) (SecurityDescription, error) {
+	var err error
+	const op = "GetSecurityDescription"
+	result := SecurityDescription{}
+
+	r := &request{
+		method:  http.MethodGet,
+		fullURL: NewIssRequest().WithSecurities(true).Target(secid).Only("description,boards").Json().MetaData(false).URL(),
+	}
+
+	var resp Response
+	err = c.getData(r, &resp)
+	if err != nil {
+		slog.Error(op+".getData", "err", err.Error())
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	items := make([]DescriptionItem, 0, len(resp.Description.Data))
+	err = Unmarshal(resp.Description.Columns, resp.Description.Data, &items)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(items) == 0 {
+		return result, fmt.Errorf("%s: %w: %s", op, ErrTickerNotFound, secid)
+	}
+
+	// строки name = value развернем в одну строку с колонками name
+	columns := make([]string, len(items))
+	row := make([]interface{}, len(items))
+	for i, item := range items {
+		columns[i], row[i] = item.Name, item.Value
+	}
+	list := make([]SecurityDescription, 0, 1)
+	err = Unmarshal(columns, [][]interface{}{row}, &list)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	result = list[0]
+	result.Items = items
+
+	result.Boards = make(SecurityBoards, 0, len(resp.Boards.Data))
+	err = Unmarshal(resp.Boards.Columns, resp.Boards.Data, &result.Boards)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
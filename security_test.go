@@ -0,0 +1,100 @@
+package iss
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestGetSecurityDescription(t *testing.T) {
+	f := newFakeISS()
+	for secid, fixture := range map[string]string{
+		"SBER":         "description_share.json",
+		"SU26238RMFS4": "description_bond.json",
+		"SiU4":         "description_future.json",
+	} {
+		body := string(readFixture(t, fixture))
+		f.handle("securities/"+secid+".json", func(url.Values) string { return body })
+	}
+	client := newFakeClient(t, f)
+
+	t.Run("акция", func(t *testing.T) {
+		d, err := client.GetSecurityDescription("SBER")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.SecID != "SBER" || d.Name != "ПАО Сбербанк" || d.ISIN != "RU0009029540" || d.IssueSize != 21586948000 ||
+			d.FaceValue != 3 || d.ListLevel != 1 || d.Type != "common_share" || d.Group != "stock_shares" || d.EmitterID != 1199 {
+			t.Errorf("описание %+v", d)
+		}
+		if d.MatDate != "" {
+			t.Errorf("MatDate у акции: %s", d.MatDate)
+		}
+		if len(d.Items) != 17 || len(d.Boards) != 3 {
+			t.Fatalf("параметров %d, режимов %d", len(d.Items), len(d.Boards))
+		}
+		if board, ok := d.Boards.Primary(); !ok || board.BoardID != "TQBR" || board.MarketBoard() != MarketShares {
+			t.Errorf("основной режим %+v", board)
+		}
+	})
+
+	t.Run("облигация", func(t *testing.T) {
+		d, err := client.GetSecurityDescription("SU26238RMFS4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.ShortName != "ОФЗ 26238" || d.FaceValue != 1000 || d.IssueSize != 500000000000 || d.MatDate != "2041-05-15" || d.Type != "ofz_bond" {
+			t.Errorf("описание %+v", d)
+		}
+		if value, ok := d.Get("COUPONPERCENT"); !ok || value != "7.100" {
+			t.Errorf("COUPONPERCENT = %q %v", value, ok)
+		}
+		// пустое значение есть в списке параметров
+		if value, ok := d.Get("EARLYREPAYMENT"); !ok || value != "" {
+			t.Errorf("EARLYREPAYMENT = %q %v", value, ok)
+		}
+		if board, ok := d.Boards.Primary(); !ok || board.MarketBoard() != MarketOFZ {
+			t.Errorf("основной режим %+v", board)
+		}
+	})
+
+	// у фьючерса нет ISIN и номинала, объем выпуска пустой, номинал = null: нулевые значения без ошибки
+	t.Run("фьючерс", func(t *testing.T) {
+		d, err := client.GetSecurityDescription("SiU4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.SecID != "SiU4" || d.ShortName != "Si-9.24" || d.Type != "futures" || d.Group != "futures_forts" {
+			t.Errorf("описание %+v", d)
+		}
+		if d.ISIN != "" || d.IssueSize != 0 || d.FaceValue != 0 || d.EmitterID != 0 || d.ListLevel != 0 {
+			t.Errorf("пустые значения %+v", d)
+		}
+		if value, ok := d.Get("LSTTRADE"); !ok || value != "2024-09-19" {
+			t.Errorf("LSTTRADE = %q %v", value, ok)
+		}
+		if value, ok := d.Get("FACEVALUE"); !ok || value != "" {
+			t.Errorf("FACEVALUE = %q %v", value, ok)
+		}
+		if _, ok := d.Get("COUPONPERCENT"); ok {
+			t.Error("COUPONPERCENT у фьючерса")
+		}
+		if len(d.Boards) != 1 || d.Boards[0].MarketBoard() != MarketFutures {
+			t.Errorf("режимы %+v", d.Boards)
+		}
+		// параметры контракта для непрерывного фьючерса
+		if c := descriptionContract(d); c.LastTradeDate != "2024-09-19" || c.AssetCode != "Si" {
+			t.Errorf("контракт %+v", c)
+		}
+	})
+
+	t.Run("нет инструмента", func(t *testing.T) {
+		f.handle("securities/NOSUCH.json", static(map[string]Table{
+			"description": table(descriptionColumns),
+			"boards":      table(boardColumns),
+		}))
+		if _, err := client.GetSecurityDescription("NOSUCH"); !errors.Is(err, ErrTickerNotFound) {
+			t.Fatalf("ошибка %v", err)
+		}
+	})
+}
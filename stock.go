@@ -111,9 +111,9 @@ func (c *Client) GetStockInfo(symbols string) ([]StockInfo, error) {
 	}
 
 	var resp Response
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -134,16 +134,16 @@ func (c *Client) GetStockData(symbols string) ([]StockData, error) {
 	var err error
 	const op = "GetStockData"
 
-	url := NewIssRequest().Stock().Json().MetaData(false).OnlyMarketData().Symbols(symbols).URL()
+	url := NewIssRequest().Stock().Json().MetaData(false).OnlyMarketData().ColumnsOf("marketdata", StockData{}).Symbols(symbols).URL()
 	r := &request{
 		method:  http.MethodGet,
 		fullURL: url,
 	}
 
 	var resp Response
-	err = c.getJSON(r, &resp)
+	err = c.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
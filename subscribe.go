@@ -0,0 +1,193 @@
+package iss
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Периоды опроса SubscribeCandles.
+var (
+	SubscribePoll     = 5 * time.Second // во время торговых сессий
+	SubscribeIdlePoll = time.Minute     // вне торговых сессий и максимальная пауза после ошибки
+)
+
+// CandleEventKind тип события подписки на свечи
+type CandleEventKind int
+
+const (
+	CandleUpdated CandleEventKind = iota // изменилась текущая (формирующаяся) свеча
+	CandleClosed                         // свеча завершена и больше не изменится
+	CandleError                          // ошибка запроса (подписка продолжает работу) или не верный интервал
+)
+
+// String название события
+func (k CandleEventKind) String() string {
+	switch k {
+	case CandleUpdated:
+		return "Updated"
+	case CandleClosed:
+		return "Closed"
+	case CandleError:
+		return "Error"
+	}
+	return "неизвестно"
+}
+
+// CandleEvent событие подписки на свечи
+type CandleEvent struct {
+	Kind   CandleEventKind
+	Candle Candle
+	Err    error // для CandleError
+}
+
+// SubscribeCandles подписка на свечи текущего дня опросом iss
+// первый запрос с from = сегодня, далее с начала последней свечи
+// каждая свеча приходит один раз как Closed, формирующаяся = Updated при каждом изменении
+// свеча закрывается, когда появилась следующая (или сменился день): без авторизации данные задержаны,
+// поэтому время окончания свечи не используется
+// вне торговых сессий (DefaultSessions) опрос реже, после ошибок пауза растет до SubscribeIdlePoll
+// канал закрывается после отмены ctx
+// не поддерживаемый интервал = одно событие CandleError с ErrInterval и канал сразу закрыт (опрос не запускается)
+func (t *Ticker) SubscribeCandles(ctx context.Context, interval Interval) <-chan CandleEvent {
+	ch := make(chan CandleEvent, 16)
+	if !interval.IsValid() {
+		ch <- CandleEvent{Kind: CandleError, Err: fmt.Errorf("SubscribeCandles: %w: %d", ErrInterval, int(interval))}
+		close(ch)
+		return ch
+	}
+	go func() {
+		defer close(ch)
+		sub := candleSubscription{ticker: t, interval: interval, ch: ch}
+		sub.run(ctx)
+	}()
+	return ch
+}
+
+// candleSubscription состояние подписки
+type candleSubscription struct {
+	ticker   *Ticker
+	interval Interval
+	ch       chan<- CandleEvent
+	day      time.Time // текущий торговый день
+	last     Candle    // последняя (формирующаяся) свеча, еще не отправлена как Closed
+	hasLast  bool      // last заполнена
+}
+
+func (s *candleSubscription) run(ctx context.Context) {
+	errDelay := SubscribePoll
+	for {
+		delay := SubscribePoll
+		if !inSession(time.Now(), DefaultSessions) {
+			delay = SubscribeIdlePoll
+		}
+		if err := s.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !s.send(ctx, CandleEvent{Kind: CandleError, Err: err}) {
+				return
+			}
+			delay = errDelay
+			errDelay = min(errDelay*2, SubscribeIdlePoll)
+		} else {
+			errDelay = SubscribePoll
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// poll один запрос свечей и рассылка событий
+func (s *candleSubscription) poll(ctx context.Context) error {
+	now := time.Now()
+	today := Interval_D1.Truncate(now)
+	if !today.Equal(s.day) {
+		// новый день: незакрытую свечу прошлого дня закроем
+		if s.hasLast && !s.send(ctx, CandleEvent{Kind: CandleClosed, Candle: s.last}) {
+			return ctx.Err()
+		}
+		s.day, s.hasLast = today, false
+	}
+
+	t := s.ticker
+	from := FormatDate(today)
+	if s.hasLast {
+		from = s.last.Begin
+	}
+	// till = конец дня (пустой till заменяется границами истории = лишний запрос)
+	till := FormatDate(today.Add(24*time.Hour - time.Second))
+	service := t.client.NewCandlesService(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol, s.interval, from, till)
+	candles, err := service.Do()
+	if err != nil {
+		return err
+	}
+	data := candles.Data
+	slices.SortStableFunc(data, func(a, b Candle) int {
+		return a.Time().Compare(b.Time())
+	})
+
+	for i, candle := range data {
+		if s.hasLast && candle.Begin < s.last.Begin {
+			// уже закрытая свеча
+			continue
+		}
+		if s.hasLast && candle.Begin == s.last.Begin {
+			if candle == s.last {
+				continue
+			}
+			s.last = candle
+			if i < len(data)-1 {
+				continue // закроем ниже при появлении следующей
+			}
+			if !s.send(ctx, CandleEvent{Kind: CandleUpdated, Candle: candle}) {
+				return ctx.Err()
+			}
+			continue
+		}
+		// новая свеча = предыдущая завершена
+		if s.hasLast && !s.send(ctx, CandleEvent{Kind: CandleClosed, Candle: s.last}) {
+			return ctx.Err()
+		}
+		s.last, s.hasLast = candle, true
+		if i == len(data)-1 {
+			if !s.send(ctx, CandleEvent{Kind: CandleUpdated, Candle: candle}) {
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+// send отправим событие, false = подписка отменена
+func (s *candleSubscription) send(ctx context.Context, event CandleEvent) bool {
+	select {
+	case s.ch <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// inSession время попадает в одну из торговых сессий (будние дни)
+func inSession(t time.Time, sessions []Session) bool {
+	t = t.In(TzMsk)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	offset := t.Sub(Interval_D1.Truncate(t))
+	for _, session := range sessions {
+		if session.Contains(offset) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package iss
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribeCandlesInterval(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	ticker, err := newFakeClient(t, f).GetTicker("SBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := ticker.SubscribeCandles(context.Background(), Interval(5))
+	event, ok := <-ch
+	if !ok || event.Kind != CandleError || !errors.Is(event.Err, ErrInterval) {
+		t.Fatalf("событие %+v", event)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("канал не закрыт")
+	}
+	if n := f.count("engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json"); n != 0 {
+		t.Fatalf("запросов свечей: %d", n)
+	}
+}
+
+func TestSubscribeCandlesPoll(t *testing.T) {
+	now := time.Now().In(TzMsk).Truncate(time.Minute)
+	if now.Sub(Interval_D1.Truncate(now)) < 5*time.Minute {
+		t.Skip("начало дня: свечи за 3 минуты попадут во вчерашний день")
+	}
+	minute := func(n int, close float64) []interface{} {
+		begin := now.Add(time.Duration(n-3) * time.Minute)
+		return []interface{}{close, close, close, close, 1e6, 1e3, begin.Format(layout), begin.Add(59 * time.Second).Format(layout)}
+	}
+
+	f := newFakeISS()
+	fakeSber(f)
+	var mu sync.Mutex
+	var rows [][]interface{}
+	f.handle("engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json", func(q url.Values) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if q.Get("start") != "" {
+			return issJSON(map[string]Table{"candles": table(candleColumns)})
+		}
+		return issJSON(map[string]Table{"candles": table(candleColumns, rows...)})
+	})
+	ticker, err := newFakeClient(t, f).GetTicker("SBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan CandleEvent, 16)
+	sub := candleSubscription{ticker: ticker, interval: Interval_M1, ch: ch}
+	type event struct {
+		kind  CandleEventKind
+		close float64
+	}
+	steps := []struct {
+		rows [][]interface{}
+		want []event
+	}{
+		// первая свеча закрыта, вторая формируется
+		{[][]interface{}{minute(0, 1), minute(1, 2)}, []event{{CandleClosed, 1}, {CandleUpdated, 2}}},
+		// формирующаяся свеча изменилась
+		{[][]interface{}{minute(1, 3)}, []event{{CandleUpdated, 3}}},
+		// без изменений = нет событий
+		{[][]interface{}{minute(1, 3)}, nil},
+		// появилась следующая = предыдущая закрыта один раз
+		{[][]interface{}{minute(1, 3), minute(2, 4)}, []event{{CandleClosed, 3}, {CandleUpdated, 4}}},
+		{[][]interface{}{minute(2, 4)}, nil},
+	}
+	for i, step := range steps {
+		mu.Lock()
+		rows = step.rows
+		mu.Unlock()
+		if err := sub.poll(context.Background()); err != nil {
+			t.Fatalf("шаг %d: %v", i, err)
+		}
+		var got []event
+		for len(ch) > 0 {
+			e := <-ch
+			got = append(got, event{e.Kind, e.Candle.Close})
+		}
+		if len(got) != len(step.want) {
+			t.Fatalf("шаг %d: события %v, ожидали %v", i, got, step.want)
+		}
+		for j := range got {
+			if got[j] != step.want[j] {
+				t.Fatalf("шаг %d: события %v, ожидали %v", i, got, step.want)
+			}
+		}
+	}
+
+	// смена дня: формирующаяся свеча закрывается, отмена ctx прерывает отправку
+	sub.day = sub.day.AddDate(0, 0, -1)
+	sub.ch = make(chan CandleEvent)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sub.poll(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("poll после отмены: %v", err)
+	}
+}
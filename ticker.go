@@ -21,18 +21,29 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 var ErrTickerNotFound = errors.New("Ticker not found")
-var ErrTickerSymbol = errors.New("код тикера должен быть больше 3-х символов")
+var ErrTickerSymbol = errors.New("код тикера не задан")
 
-//type TickerOption = func(*Ticker)
-//
-//func WithBoard(board string) TickerOption {
-//	return func(t *Ticker) {
-//		t.issRequest.boards = board
-//	}
-//}
+// TickerOption параметры поиска тикера
+type TickerOption = func(*Ticker)
+
+// WithBoard искать тикер в заданном режиме торгов (TQBR, TQOB, CETS, SNDX ...)
+func WithBoard(board string) TickerOption {
+	return func(t *Ticker) {
+		t.issRequest = t.issRequest.Boards(board)
+	}
+}
+
+// WithMarket искать тикер на заданном рынке (MarketBonds, MarketCurrency ...)
+// режим торгов m.Board не учитывается, для него есть WithBoard
+func WithMarket(m Market) TickerOption {
+	return func(t *Ticker) {
+		t.issRequest = t.issRequest.Engines(m.Engine).Markets(m.Market)
+	}
+}
 
 type Ticker struct {
 	symbol      string
@@ -96,11 +107,12 @@ type TickerData struct {
 	TradingSession  string  `json:"TRADINGSESSION"`  // Торговая сессия
 }
 
-// GetTicker поиск тикера
-// func (c *Client) NewTicker(symbol string, opts ...TickerOption) (*Ticker, error) {
-func (c *Client) GetTicker(symbol string) (*Ticker, error) {
-	// сразу отфильтруем короткий код символа
-	if len(symbol) < 3 {
+// GetTicker поиск тикера по всем рынкам (акции, облигации, фонды, валюта, индексы, фьючерсы, опционы)
+// режим торгов берется из списка режимов инструмента: основной (is_primary), затем торгуемый
+// фьючерс можно искать и по краткому названию ("Si-9.24")
+// рынок и режим торгов можно задать явно: WithMarket, WithBoard
+func (c *Client) GetTicker(symbol string, opts ...TickerOption) (*Ticker, error) {
+	if symbol == "" {
 		return nil, ErrTickerSymbol
 	}
 	iss := NewIssRequest().Json().MetaData(false)
@@ -110,86 +122,71 @@ func (c *Client) GetTicker(symbol string) (*Ticker, error) {
 		issRequest: iss,
 	}
 
-	//for _, opt := range opts {
-	//	opt(t)
-	//}
+	for _, opt := range opts {
+		opt(t)
+	}
 
-	//  раздельный поиск по акциям и фьючам
-	// поиск среди акций
-	exists, err := t.getStock()
+	// поиск по коду среди режимов торгов всех рынков
+	exists, err := t.getBoard()
 	if err != nil {
 		return nil, err
 	}
 	if exists {
 		return t, nil
 	}
-	// поиск среди фьючерсов
-	exists, err = t.getForts()
-	if err != nil {
-		return nil, err
-	}
-	if exists {
-		return t, nil
+	// поиск фьючерса по краткому названию
+	if matchFilter(t.issRequest.engines, "futures") && matchFilter(t.issRequest.markets, "forts") && matchFilter(t.issRequest.boards, FortsBoard) {
+		exists, err = t.getForts()
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return t, nil
+		}
 	}
 
 	// если дошли до сюда = значит НЕ нашли такой тикер
 	return t, ErrTickerNotFound
 }
 
-// getStock поиск тикера среди акций
-func (t *Ticker) getStock() (bool, error) {
-
-	sec, err := t.client.GetStockInfo(t.symbol)
+// getBoard поиск тикера по режимам торгов /iss/securities/{secid}.json
+func (t *Ticker) getBoard() (bool, error) {
+	boards, err := t.client.GetSecurityBoards(t.symbol)
 	if err != nil {
 		return false, err
 	}
-	if len(sec) == 1 {
-		t.issRequest.securities = true
-		t.issRequest.symbol = t.symbol
-		t.issRequest.engines = "stock"
-		t.issRequest.markets = "shares"
-		t.issRequest.boards = StockBoard
-
-		t.SecID = sec[0].SecID
-		t.ShortName = sec[0].ShortName
-		t.SecName = sec[0].SecName
-		t.MinStep = sec[0].MinStep
-		t.SecType = sec[0].SecType
-		t.Decimals = sec[0].Decimals
-		return true, nil
+	board, ok := boards.Select(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards)
+	if !ok {
+		return false, nil
 	}
-	return false, nil
-}
+	t.issRequest = t.issRequest.WithSecurities(true).Symbol(board.SecID).
+		Engines(board.Engine).Markets(board.Market).Boards(board.BoardID)
 
-// getForts поиск тикера среди фьючерсов
-func (t *Ticker) getForts() (bool, error) {
-	// TODO получился не очень красивый код = подумать как сделать рефакторинг
-	// поиск по коду (SiU4)
-	sec, err := t.client.GetFortsInfo(t.symbol)
+	info, err := t.Info()
+	if errors.Is(err, ErrTickerNotFound) {
+		// режим есть в справочнике, но инструмента на нем уже нет
+		t.SecID, t.Decimals = board.SecID, board.Decimals
+		return true, nil
+	}
 	if err != nil {
 		return false, err
 	}
-	if len(sec) == 1 {
-		t.issRequest.securities = true
-		t.issRequest.symbol = t.symbol
-		t.issRequest.engines = "futures"
-		t.issRequest.markets = "forts"
-		t.issRequest.boards = FortsBoard
-
-		t.SecID = sec[0].SecID
-		t.ShortName = sec[0].ShortName
-		t.SecName = sec[0].SecName
-		t.MinStep = sec[0].MinStep
-		t.SecType = sec[0].SecType
-		t.Decimals = sec[0].Decimals
-		t.AssetCode = sec[0].AssetCode
-		t.LastDelDate = sec[0].LastDelDate
+	t.SecID = info.SecID
+	t.ShortName = info.ShortName
+	t.SecName = info.SecName
+	t.MinStep = info.MinStep
+	t.SecType = info.SecType
+	t.Decimals = info.Decimals
+	t.AssetCode = info.AssetCode
+	t.LastDelDate = info.LastDelDate
+	return true, nil
+}
 
-		return true, nil
-	}
-	// поиск по названию "Si-9.24"
+// getForts поиск тикера среди фьючерсов
+func (t *Ticker) getForts() (bool, error) {
+	// поиск по названию "Si-9.24" (по коду SiU4 найдет getBoard)
 	// поиск перебором по списку и поиск по ShortName
-	sec, err = t.client.GetFortsInfo("")
+	sec, err := t.client.GetFortsInfo("")
 	if err != nil {
 		return false, err
 	}
@@ -197,11 +194,8 @@ func (t *Ticker) getForts() (bool, error) {
 	for _, _sec := range sec {
 		// если нашли = выйдем
 		if _sec.ShortName == t.symbol {
-			t.issRequest.securities = true
-			t.issRequest.symbol = _sec.SecID
-			t.issRequest.engines = "futures"
-			t.issRequest.markets = "forts"
-			t.issRequest.boards = FortsBoard
+			t.issRequest = t.issRequest.WithSecurities(true).Symbol(_sec.SecID).
+				Engines("futures").Markets("forts").Boards(FortsBoard)
 
 			t.SecID = _sec.SecID
 			t.ShortName = _sec.ShortName
@@ -232,9 +226,9 @@ func (t *Ticker) Info() (TickerInfo, error) {
 	}
 
 	var resp Response
-	err = t.client.getJSON(r, &resp)
+	err = t.client.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return result, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -244,6 +238,9 @@ func (t *Ticker) Info() (TickerInfo, error) {
 		slog.Error(op+".Unmarshal", "err", err.Error())
 		return result, fmt.Errorf("%s: %w", op, err)
 	}
+	if len(list) == 0 {
+		return result, fmt.Errorf("%s: %w", op, ErrTickerNotFound)
+	}
 	result = list[0]
 	return result, nil
 }
@@ -261,9 +258,9 @@ func (t *Ticker) Data() (TickerData, error) {
 	}
 
 	var resp Response
-	err = t.client.getJSON(r, &resp)
+	err = t.client.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return result, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -273,15 +270,44 @@ func (t *Ticker) Data() (TickerData, error) {
 		slog.Error(op+".Unmarshal", "err", err.Error())
 		return result, fmt.Errorf("%s: %w", op, err)
 	}
+	if len(list) == 0 {
+		return result, fmt.Errorf("%s: %w", op, ErrTickerNotFound)
+	}
 	result = list[0]
 	return result, nil
 }
 
 // Candles получим исторические свечи
-func (t *Ticker) Candles(interval int, from, to string) (Candles, error) {
+// пустой from = вся доступная история (границы candleborders)
+func (t *Ticker) Candles(interval Interval, from, to time.Time) (Candles, error) {
 	//return t.client.NewCandlesService("stock", "shares", StockBoard, symbols, int(interval), from, to).Do()
-	return t.client.NewCandlesService(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol, int(interval), from, to).Do()
+	s := t.client.NewCandlesService(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol, interval, "", "")
+	s.issRequest = s.issRequest.DateRange(from, to)
+	if from.IsZero() {
+		s.ClampToBorders()
+	}
+	return s.Do()
+
+}
+
+// Market рынок и режим торгов, на котором найден тикер
+func (t *Ticker) Market() Market {
+	return Market{Engine: t.issRequest.engines, Market: t.issRequest.markets, Board: t.issRequest.boards}
+}
+
+// Description описание инструмента (ISIN, номинал, уровень листинга, эмитент ...) и все режимы торгов
+func (t *Ticker) Description() (SecurityDescription, error) {
+	return t.client.GetSecurityDescription(t.issRequest.symbol)
+}
 
+// CandleBorders границы доступной истории свечей по тикеру
+func (t *Ticker) CandleBorders() (CandleBorders, error) {
+	return t.client.GetCandleBorders(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol)
+}
+
+// CandlesTimeframe исторические свечи по тикеру в произвольном таймфрейме (M5 M15 M30 H4 ...)
+func (t *Ticker) CandlesTimeframe(tf Timeframe, from, to time.Time, sessions ...Session) (Candles, error) {
+	return t.client.GetCandles(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol, tf, from, to, sessions...)
 }
 
 // OrderBook Возвращает текущий стакан лучших цен
@@ -289,3 +315,9 @@ func (t *Ticker) Candles(interval int, from, to string) (Candles, error) {
 func (t *Ticker) OrderBook() (OrderBook, error) {
 	return t.client.NewOrderBookService(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol).Do()
 }
+
+// Trades сделки текущего дня по тикеру
+// нужна авторизация
+func (t *Ticker) Trades() ([]Trade, error) {
+	return t.client.GetTrades(t.issRequest.engines, t.issRequest.markets, t.issRequest.boards, t.issRequest.symbol)
+}
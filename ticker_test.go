@@ -0,0 +1,137 @@
+package iss
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeSecurity инструмент на одном режиме торгов: режимы торгов, securities и marketdata
+// пустой shortName = режим есть в справочнике, но securities и marketdata пустые
+func fakeSecurity(f *fakeISS, m Market, secid, shortName string, last float64) {
+	f.handle("securities/"+secid+".json", static(map[string]Table{
+		"boards": table(boardColumns,
+			[]interface{}{secid, m.Board, m.Market, m.Engine, 1, 2, 1}),
+	}))
+	blocks := map[string]Table{
+		"securities": table([]string{"SECID", "BOARDID", "SHORTNAME", "DECIMALS", "MINSTEP"}),
+		"marketdata": table([]string{"SECID", "BOARDID", "LAST"}),
+	}
+	if shortName != "" {
+		blocks["securities"] = table([]string{"SECID", "BOARDID", "SHORTNAME", "DECIMALS", "MINSTEP"},
+			[]interface{}{secid, m.Board, shortName, 2, 0.01})
+		blocks["marketdata"] = table([]string{"SECID", "BOARDID", "LAST"},
+			[]interface{}{secid, m.Board, last})
+	}
+	f.handle("engines/"+m.Engine+"/markets/"+m.Market+"/boards/"+m.Board+"/securities/"+secid+".json", static(blocks))
+}
+
+func TestGetTickerMarkets(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	fakeSecurity(f, MarketOFZ, "SU26238RMFS4", "ОФЗ 26238", 57.3)
+	fakeSecurity(f, MarketBonds, "RU000A105KU0", "Сбер Sb37R", 99.8)
+	fakeSecurity(f, MarketCurrency, "CNYRUB_TOM", "CNYRUB_TOM - ЮАНЬ/РУБ", 12.1)
+	fakeSecurity(f, MarketIndex, "IMOEX", "Индекс МосБиржи", 2950.4)
+	fakeSecurity(f, MarketOptions, "Si92500BI4", "Si-9.24M190924CA92500", 310)
+	fakeSecurity(f, MarketFutures, "SiU4", "Si-9.24", 91200)
+	// по краткому названию фьючерса режимов торгов нет = поиск по списку фортс
+	f.handle("securities/Si-9.24.json", static(map[string]Table{"boards": table(boardColumns)}))
+	f.handle("engines/futures/markets/forts/securities.json", static(map[string]Table{
+		"securities": table([]string{"SECID", "BOARDID", "SHORTNAME", "SECNAME", "DECIMALS", "MINSTEP", "ASSETCODE", "LASTDELDATE"},
+			[]interface{}{"SiZ4", "RFUD", "Si-12.24", "Фьючерсный контракт Si-12.24", 0, 1, "Si", "2024-12-20"},
+			[]interface{}{"SiU4", "RFUD", "Si-9.24", "Фьючерсный контракт Si-9.24", 0, 1, "Si", "2024-09-20"},
+		),
+	}))
+	client := newFakeClient(t, f)
+
+	tests := []struct {
+		symbol    string
+		market    Market
+		secid     string
+		shortName string
+		last      float64
+	}{
+		{"SBER", MarketShares, "SBER", "Сбербанк", 306.1},
+		{"SU26238RMFS4", MarketOFZ, "SU26238RMFS4", "ОФЗ 26238", 57.3},
+		{"RU000A105KU0", MarketBonds, "RU000A105KU0", "Сбер Sb37R", 99.8},
+		{"CNYRUB_TOM", MarketCurrency, "CNYRUB_TOM", "CNYRUB_TOM - ЮАНЬ/РУБ", 12.1},
+		{"IMOEX", MarketIndex, "IMOEX", "Индекс МосБиржи", 2950.4},
+		{"Si92500BI4", MarketOptions, "Si92500BI4", "Si-9.24M190924CA92500", 310},
+		{"SiU4", MarketFutures, "SiU4", "Si-9.24", 91200},
+		{"Si-9.24", MarketFutures, "SiU4", "Si-9.24", 91200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			ticker, err := client.GetTicker(tt.symbol)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ticker.Market() != tt.market {
+				t.Errorf("рынок %+v, ожидали %+v", ticker.Market(), tt.market)
+			}
+			if ticker.SecID != tt.secid || ticker.ShortName != tt.shortName {
+				t.Errorf("тикер %s %s", ticker.SecID, ticker.ShortName)
+			}
+			data, err := ticker.Data()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.SecID != tt.secid || data.Last != tt.last {
+				t.Errorf("Data %+v", data)
+			}
+		})
+	}
+
+	// фьючерс по краткому названию: параметры из списка фортс
+	ticker, err := client.GetTicker("Si-9.24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.AssetCode != "Si" || ticker.LastDelDate != "2024-09-20" {
+		t.Errorf("фьючерс %+v", ticker)
+	}
+}
+
+func TestGetTickerNotFound(t *testing.T) {
+	f := newFakeISS()
+	fakeSber(f)
+	f.handle("securities/NOSUCH.json", static(map[string]Table{"boards": table(boardColumns)}))
+	f.handle("engines/futures/markets/forts/securities.json", static(map[string]Table{
+		"securities": table([]string{"SECID", "SHORTNAME"}),
+	}))
+	// режим торгов есть в справочнике, но инструмента на нем уже нет
+	fakeSecurity(f, MarketBonds, "RU000A0JX0J2", "", 0)
+	client := newFakeClient(t, f)
+
+	if _, err := client.GetTicker(""); !errors.Is(err, ErrTickerSymbol) {
+		t.Fatalf("пустой код: %v", err)
+	}
+	if _, err := client.GetTicker("NOSUCH"); !errors.Is(err, ErrTickerNotFound) {
+		t.Fatalf("неизвестный код: %v", err)
+	}
+	// на другом рынке не ищем, по названию фьючерса = только на срочном рынке
+	if _, err := client.GetTicker("SBER", WithMarket(MarketBonds)); !errors.Is(err, ErrTickerNotFound) {
+		t.Fatalf("SBER среди облигаций: %v", err)
+	}
+	if n := f.count("engines/futures/markets/forts/securities.json"); n != 1 {
+		t.Errorf("поиск по списку фортс: %d запросов, ожидали 1 (только для NOSUCH)", n)
+	}
+	if ticker, err := client.GetTicker("SBER", WithBoard("SMAL")); err != nil || ticker.Market().Board != "SMAL" {
+		t.Fatalf("SBER на SMAL: %v", err)
+	}
+
+	// пустые securities и marketdata = ErrTickerNotFound (а не пустой результат)
+	ticker, err := client.GetTicker("RU000A0JX0J2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.SecID != "RU000A0JX0J2" || ticker.Decimals != 2 {
+		t.Errorf("тикер по режиму торгов: %+v", ticker)
+	}
+	if _, err := ticker.Info(); !errors.Is(err, ErrTickerNotFound) {
+		t.Errorf("Info: %v", err)
+	}
+	if _, err := ticker.Data(); !errors.Is(err, ErrTickerNotFound) {
+		t.Errorf("Data: %v", err)
+	}
+}
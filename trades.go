@@ -0,0 +1,192 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+Сделки текущего торгового дня (лента)
+только по авторизации
+
+// по одному инструменту
+https://iss.moex.com/iss/engines/stock/markets/shares/boards/TQBR/securities/SBER/trades.json
+// по всему рынку (режиму торгов)
+https://iss.moex.com/iss/engines/stock/markets/shares/boards/TQBR/trades.json
+// следующая страница = сделки после заданного номера
+https://iss.moex.com/iss/engines/stock/markets/shares/trades.json?tradeno=11305543421&next_trade=1
+*/
+
+// Trade сделка
+type Trade struct {
+	TradeNo        int64   `json:"TRADENO"`        // номер сделки (растет в течение дня)
+	TradeDate      string  `json:"TRADEDATE"`      // дата сделки (есть не на всех рынках)
+	TradeTime      string  `json:"TRADETIME"`      // время сделки
+	BoardID        string  `json:"BOARDID"`        // режим торгов
+	SecID          string  `json:"SECID"`          // код инструмента
+	Price          float64 `json:"PRICE"`          // цена
+	Quantity       int64   `json:"QUANTITY"`       // количество в лотах
+	Value          float64 `json:"VALUE"`          // объем в рублях
+	BuySell        string  `json:"BUYSELL"`        // B = покупка, S = продажа (направление заявки инициатора)
+	TradingSession string  `json:"TRADINGSESSION"` // торговая сессия (0 = утренняя, 1 = основная, 2 = вечерняя)
+	OpenPosition   int64   `json:"OPENPOSITION"`   // открытые позиции после сделки (фьючерсы)
+	Decimals       int     `json:"DECIMALS"`       // точность цены
+	SysTime        string  `json:"SYSTIME"`        // время загрузки данных системой
+}
+
+// Time время сделки (по московскому времени)
+// если рынок не присылает TRADEDATE = дата берется из SYSTIME
+func (t Trade) Time() time.Time {
+	date := t.TradeDate
+	if date == "" && len(t.SysTime) >= len(dateLayout) {
+		date = t.SysTime[:len(dateLayout)]
+	}
+	tm, _ := time.ParseInLocation(layout, date+" "+t.TradeTime, TzMsk)
+	return tm
+}
+
+// Tick сделка для построения альтернативных баров (BarBuilder)
+// если рынок не присылает VALUE (фьючерсы) = оборот не заполнен
+func (t Trade) Tick() Tick {
+	return Tick{Time: t.Time(), Price: t.Price, Quantity: float64(t.Quantity), Value: t.Value}
+}
+
+// TradesService сервис для получения сделок
+// страницы запрашиваются по номеру последней полученной сделки (tradeno + next_trade),
+// поэтому новые сделки во время выгрузки не сдвигают страницы
+type TradesService struct {
+	client     *Client
+	issRequest *IssRequest // не изменяемый
+	mu         sync.Mutex
+	tradeNo    int64 // номер последней полученной сделки (0 = с начала дня)
+}
+
+// NewTradesService создание сервиса
+// symbol == "" = сделки по всему рынку (режиму торгов, если задан board)
+func (c *Client) NewTradesService(engines, markets, board, symbol string) *TradesService {
+	iss := NewIssRequest().
+		Engines(engines).
+		Markets(markets).
+		Boards(board).
+		WithSecurities(symbol != "").
+		Symbol(symbol).
+		Target("trades").
+		Json().MetaData(false)
+
+	return &TradesService{
+		client:     c,
+		issRequest: iss,
+	}
+}
+
+// clone копия сервиса с начала дня
+func (s *TradesService) clone() *TradesService {
+	return &TradesService{client: s.client, issRequest: s.issRequest}
+}
+
+// URL строка запроса первой страницы
+func (s *TradesService) URL() string {
+	return s.issRequest.URL()
+}
+
+// FromTradeNo выгружать сделки после сделки с номером tradeNo
+func (s *TradesService) FromTradeNo(tradeNo int64) *TradesService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tradeNo = tradeNo
+	return s
+}
+
+// TradeNo номер последней полученной сделки (0 = сделок еще не было)
+func (s *TradesService) TradeNo() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tradeNo
+}
+
+// Reset начать выгрузку заново (с начала дня)
+func (s *TradesService) Reset() {
+	s.FromTradeNo(0)
+}
+
+// Next загружает следующую страницу сделок
+// Если новых сделок нет, то возвращается ошибка EOF
+// запрос должен выполнятся только с авторизацией
+func (s *TradesService) Next() ([]Trade, error) {
+	var err error
+	const op = "TradesService.Next"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.issRequest.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	req := s.issRequest
+	if s.tradeNo != 0 {
+		req = req.Param("tradeno", strconv.FormatInt(s.tradeNo, 10)).Param("next_trade", 1)
+	}
+	r := &request{
+		method:            http.MethodGet,
+		fullURL:           req.URL(),
+		authorizationOnly: true,
+	}
+
+	var resp Response
+	err = s.client.getData(r, &resp)
+	if err != nil {
+		slog.Error(op+".getData", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make([]Trade, 0, len(resp.Trades.Data))
+	err = Unmarshal(resp.Trades.Columns, resp.Trades.Data, &result)
+	if err != nil {
+		slog.Error(op+".Unmarshal", "err", err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(result) == 0 {
+		return result, EOF
+	}
+	for _, trade := range result {
+		s.tradeNo = max(s.tradeNo, trade.TradeNo)
+	}
+	s.client.log.Debug(op, "tradeno", s.tradeNo, "len(result)", len(result))
+	return result, nil
+}
+
+// Do выгрузка всех сделок (после FromTradeNo, если задан)
+func (s *TradesService) Do() ([]Trade, error) {
+	const op = "TradesService.Do"
+	result := make([]Trade, 0)
+	for {
+		trades, err := s.Next()
+		if err != nil {
+			if errors.Is(err, EOF) {
+				break
+			}
+			return result, fmt.Errorf("%s: %w", op, err)
+		}
+		result = append(result, trades...)
+	}
+	return result, nil
+}
+
+// GetTrades получить сделки текущего дня по инструменту
+// нужна авторизация
+func (c *Client) GetTrades(engines, markets, board, symbol string) ([]Trade, error) {
+	return c.NewTradesService(engines, markets, board, symbol).Do()
+}
+
+// GetMarketTrades получить сделки текущего дня по всему рынку (режиму торгов m.Board, если задан)
+// пустой m.Board = сделки всех режимов торгов рынка: /engines/{engine}/markets/{market}/trades.json
+// (например Market{Engine: "stock", Market: "shares"} = TQBR, SMAL, TQTF ... вместе)
+// нужна авторизация
+func (c *Client) GetMarketTrades(m Market) ([]Trade, error) {
+	return c.NewTradesService(m.Engine, m.Market, m.Board, "").Do()
+}
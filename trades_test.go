@@ -0,0 +1,142 @@
+package iss
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+var tradeColumns = []string{"TRADENO", "TRADETIME", "BOARDID", "SECID", "PRICE", "QUANTITY", "VALUE", "BUYSELL", "TRADINGSESSION", "SYSTIME"}
+
+// fakeTrades лента сделок: страницы по 3 сделки после tradeno (next_trade=1) или с начала дня
+type fakeTrades struct {
+	mu    sync.Mutex
+	count int    // сделок за день
+	base  int    // номер перед первой сделкой дня (сделки base+1 ... base+count)
+	date  string // дата сделок (пусто = 2024-08-06)
+}
+
+func (ft *fakeTrades) add(n int) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.count += n
+}
+
+func (ft *fakeTrades) handler(q url.Values) string {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	from := ft.base
+	if q.Get("next_trade") == "1" {
+		from, _ = strconv.Atoi(q.Get("tradeno"))
+	}
+	date := ft.date
+	if date == "" {
+		date = "2024-08-06"
+	}
+	rows := make([][]interface{}, 0)
+	for no := max(from, ft.base) + 1; no <= ft.base+ft.count && len(rows) < 3; no++ {
+		tm := fmt.Sprintf("10:%02d:%02d", no/60%60, no%60)
+		rows = append(rows, []interface{}{no, tm, "TQBR", "SBER", 300.0 + float64(no), 1, 3000.0, "B", "1", date + " " + tm})
+	}
+	return issJSON(map[string]Table{"trades": table(tradeColumns, rows...)})
+}
+
+func TestTradesServicePaging(t *testing.T) {
+	const path = "engines/stock/markets/shares/boards/TQBR/securities/SBER/trades.json"
+	f := newFakeISS()
+	ft := &fakeTrades{count: 7}
+	f.handle(path, ft.handler)
+	client := newFakeClient(t, f)
+	service := client.NewTradesService("stock", "shares", StockBoard, "SBER")
+
+	trades, err := service.Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 7 || trades[6].TradeNo != 7 || service.TradeNo() != 7 {
+		t.Fatalf("сделок %d, tradeno %d", len(trades), service.TradeNo())
+	}
+	// 3 страницы + пустой ответ
+	if n := f.count(path); n != 4 {
+		t.Fatalf("запросов %d, ожидали 4", n)
+	}
+	if got := trades[6].Time().Format(layout); got != "2024-08-06 10:00:07" {
+		t.Errorf("время сделки %s", got)
+	}
+
+	// новых сделок нет = EOF, номер не меняется
+	if _, err := service.Next(); !errors.Is(err, EOF) {
+		t.Fatalf("нет новых сделок: %v", err)
+	}
+	q, _ := f.last(path)
+	if q.Get("tradeno") != "7" || q.Get("next_trade") != "1" {
+		t.Fatalf("параметры %v", q)
+	}
+	// появились новые = только они
+	ft.add(2)
+	trades, err = service.Next()
+	if err != nil || len(trades) != 2 || trades[0].TradeNo != 8 || service.TradeNo() != 9 {
+		t.Fatalf("новые сделки %v %v", trades, err)
+	}
+
+	// выгрузка после заданного номера
+	trades, err = client.NewTradesService("stock", "shares", StockBoard, "SBER").FromTradeNo(5).Do()
+	if err != nil || len(trades) != 4 || trades[0].TradeNo != 6 {
+		t.Fatalf("после 5: %v %v", trades, err)
+	}
+
+	// Reset = снова с начала дня (без tradeno)
+	service.Reset()
+	if _, err = service.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := f.last(path); q.Has("tradeno") || q.Has("next_trade") {
+		t.Fatalf("после Reset: %v", q)
+	}
+}
+
+// без авторизации сервер отвечает без заголовка granted = ErrUnauthorized (а не EOF), номер не меняется
+func TestTradesServiceUnauthorized(t *testing.T) {
+	const path = "engines/stock/markets/shares/boards/TQBR/securities/SBER/trades.json"
+	f := newFakeISS()
+	f.granted = false
+	ft := &fakeTrades{count: 3}
+	f.handle(path, ft.handler)
+	service := newFakeClient(t, f).NewTradesService("stock", "shares", StockBoard, "SBER").FromTradeNo(1)
+
+	trades, err := service.Next()
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("без авторизации: %v %v", trades, err)
+	}
+	if service.TradeNo() != 1 {
+		t.Fatalf("tradeno %d", service.TradeNo())
+	}
+	if _, err = service.Do(); err == nil {
+		t.Fatal("Do без авторизации без ошибки")
+	}
+}
+
+// сделки по рынку: пустой Board = все режимы торгов рынка (в пути нет boards)
+func TestGetMarketTradesURL(t *testing.T) {
+	f := newFakeISS()
+	ft := &fakeTrades{count: 2}
+	f.handle("engines/stock/markets/shares/trades.json", ft.handler)
+	f.handle("engines/stock/markets/shares/boards/TQBR/trades.json", ft.handler)
+	client := newFakeClient(t, f)
+
+	for _, m := range []Market{{Engine: "stock", Market: "shares"}, MarketShares} {
+		trades, err := client.GetMarketTrades(m)
+		if err != nil || len(trades) != 2 {
+			t.Fatalf("%+v: %v %v", m, trades, err)
+		}
+	}
+	if f.count("engines/stock/markets/shares/trades.json") != 2 || f.count("engines/stock/markets/shares/boards/TQBR/trades.json") != 2 {
+		t.Fatalf("запросы %v", f.requests)
+	}
+	if got := client.NewTradesService("stock", "shares", "", "").URL(); got != "https://iss.moex.com/iss/engines/stock/markets/shares/trades.json?iss.meta=off" {
+		t.Fatalf("URL %s", got)
+	}
+}
@@ -0,0 +1,361 @@
+package iss
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+/*
+Лента новых сделок: опрос iss с номером последней полученной сделки (tradeno + next_trade)
+
+сделки приходят по возрастанию TRADENO без повторов, отметка последней сделки сохраняется
+через TradeWatermark, поэтому после перезапуска лента продолжается с того же места
+при смене торгового дня и торговой сессии (по расписанию рынка) проверяется, не начата ли нумерация сделок заново:
+торговый день срочного рынка начинается с вечерней сессии (после клиринга 19:00), см. tradingDay
+*/
+
+// TradeMark отметка последней обработанной сделки
+type TradeMark struct {
+	TradeNo int64     `json:"tradeno"`
+	Time    time.Time `json:"time"` // время сделки (для проверки смены нумерации)
+}
+
+// TradeWatermark хранилище отметок последней обработанной сделки
+type TradeWatermark interface {
+	// Load отметка по ключу (false = еще не сохранялась)
+	Load(key string) (TradeMark, bool, error)
+	// Save сохранить отметку
+	Save(key string, mark TradeMark) error
+}
+
+// FileTradeWatermark отметки в json файле (ключ = рынок/режим торгов/инструмент)
+// файл перезаписывается целиком через временный файл
+type FileTradeWatermark struct {
+	path  string
+	mu    sync.Mutex
+	marks map[string]TradeMark
+}
+
+// NewFileTradeWatermark хранилище отметок в файле (если файл есть = отметки загружаются)
+func NewFileTradeWatermark(path string) (*FileTradeWatermark, error) {
+	w := &FileTradeWatermark{path: path, marks: make(map[string]TradeMark)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &w.marks); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Load отметка по ключу
+func (w *FileTradeWatermark) Load(key string) (TradeMark, bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	mark, ok := w.marks[key]
+	return mark, ok, nil
+}
+
+// Save сохранить отметку
+func (w *FileTradeWatermark) Save(key string, mark TradeMark) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.marks[key] = mark
+	data, err := json.MarshalIndent(w.marks, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), filepath.Base(w.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), w.path)
+}
+
+// TradeEventKind тип события ленты сделок
+type TradeEventKind int
+
+const (
+	TradeNew            TradeEventKind = iota // новая сделка
+	TradeSessionChanged                       // сменилась торговая сессия (TRADINGSESSION) или нумерация сделок начата заново
+	TradeError                                // ошибка запроса или сохранения отметки (лента продолжает работу, кроме ErrUnauthorized)
+)
+
+// String название события
+func (k TradeEventKind) String() string {
+	switch k {
+	case TradeNew:
+		return "New"
+	case TradeSessionChanged:
+		return "SessionChanged"
+	case TradeError:
+		return "Error"
+	}
+	return "неизвестно"
+}
+
+// TradeEvent событие ленты сделок
+type TradeEvent struct {
+	Kind  TradeEventKind
+	Trade Trade // TradeNew; TradeSessionChanged = первая сделка новой сессии
+	Err   error // для TradeError
+}
+
+// SubscribeTrades лента новых сделок по инструменту (symbol == "" = по всему рынку m)
+// wm = хранилище отметки последней сделки (nil = лента начинается с начала дня и отметка не сохраняется)
+// канал без буфера: отметка сохраняется после того, как получатель забрал все сделки страницы
+// (после сбоя получателя возможен повтор сделок последней страницы, пропусков нет)
+// периоды опроса = SubscribePoll и SubscribeIdlePoll (вне сессий по расписанию MarketSessions)
+// нужна авторизация: без нее = одно событие TradeError с ErrUnauthorized и канал закрыт
+// канал закрывается после отмены ctx
+func (c *Client) SubscribeTrades(ctx context.Context, m Market, symbol string, wm TradeWatermark) <-chan TradeEvent {
+	ch := make(chan TradeEvent)
+	go func() {
+		defer close(ch)
+		sub := tradeSubscription{
+			service:  c.NewTradesService(m.Engine, m.Market, m.Board, symbol),
+			key:      m.String() + "/" + symbol,
+			wm:       wm,
+			market:   m,
+			sessions: MarketSessions(m),
+			ch:       ch,
+		}
+		sub.run(ctx)
+	}()
+	return ch
+}
+
+// SubscribeTrades лента новых сделок по тикеру (см. Client.SubscribeTrades)
+func (t *Ticker) SubscribeTrades(ctx context.Context, wm TradeWatermark) <-chan TradeEvent {
+	return t.client.SubscribeTrades(ctx, t.Market(), t.issRequest.symbol, wm)
+}
+
+// tradeSubscription состояние ленты сделок
+type tradeSubscription struct {
+	service  *TradesService
+	key      string
+	wm       TradeWatermark
+	market   Market
+	sessions []Session
+	ch       chan<- TradeEvent
+
+	loaded  bool      // отметка загружена из хранилища
+	mark    TradeMark // последняя отправленная сделка
+	session string    // TRADINGSESSION последней сделки
+	checked bool      // нумерация проверена для текущего торгового дня и сессии
+	day     time.Time // торговый день последней проверки
+	period  Session   // сессия по расписанию при последней проверке
+}
+
+func (s *tradeSubscription) run(ctx context.Context) {
+	errDelay := SubscribePoll
+	for {
+		delay := SubscribePoll
+		if !inSession(time.Now(), s.sessions) {
+			delay = SubscribeIdlePoll
+		}
+		if err := s.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !s.send(ctx, TradeEvent{Kind: TradeError, Err: err}) {
+				return
+			}
+			if errors.Is(err, ErrUnauthorized) {
+				// без авторизации повторять запросы бесполезно
+				return
+			}
+			delay = errDelay
+			errDelay = min(errDelay*2, SubscribeIdlePoll)
+		} else {
+			errDelay = SubscribePoll
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// poll все новые сделки после отметки
+func (s *tradeSubscription) poll(ctx context.Context) error {
+	if !s.loaded && s.wm != nil {
+		mark, _, err := s.wm.Load(s.key)
+		if err != nil {
+			return err
+		}
+		s.mark = mark
+	}
+	s.loaded = true
+
+	// смена торгового дня или сессии по расписанию = проверим нумерацию
+	// пока сделок текущего торгового дня нет, проверка повторяется при каждом опросе
+	now := time.Now()
+	day := tradingDay(now, s.market)
+	_, period, _ := sessionOf(now, s.sessions)
+	if !s.checked || !day.Equal(s.day) || period != s.period {
+		checked, err := s.checkNumbering(ctx, day)
+		if err != nil {
+			return err
+		}
+		s.checked, s.day, s.period = checked, day, period
+	}
+
+	// страницы подряд, пока сервер присылает сделки
+	s.service.FromTradeNo(s.mark.TradeNo)
+	for {
+		trades, err := s.service.Next()
+		if errors.Is(err, EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = s.emit(ctx, trades); err != nil {
+			return err
+		}
+	}
+}
+
+// checkNumbering первая страница сделок торгового дня day: если все номера меньше отметки,
+// а отметка с прошлого торгового дня = нумерация начата заново, начнем ленту с начала
+// false = проверить не по чему (сделок нет или сервер еще отдает сделки прошлого дня)
+func (s *tradeSubscription) checkNumbering(ctx context.Context, day time.Time) (bool, error) {
+	if s.mark.TradeNo == 0 {
+		return true, nil
+	}
+	probe := s.service.clone()
+	trades, err := probe.Next()
+	if errors.Is(err, EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	last := slices.MaxFunc(trades, func(a, b Trade) int {
+		return a.Time().Compare(b.Time())
+	})
+	if !tradeDay(last, s.market).Equal(day) {
+		return false, nil
+	}
+	var maxNo int64
+	for _, trade := range trades {
+		maxNo = max(maxNo, trade.TradeNo)
+	}
+	if maxNo >= s.mark.TradeNo || !day.After(tradingDay(s.mark.Time, s.market)) {
+		return true, nil
+	}
+	slices.SortFunc(trades, func(a, b Trade) int {
+		return cmp.Compare(a.TradeNo, b.TradeNo)
+	})
+	s.mark, s.session = TradeMark{}, ""
+	if !s.send(ctx, TradeEvent{Kind: TradeSessionChanged, Trade: trades[0]}) {
+		return false, ctx.Err()
+	}
+	return true, nil
+}
+
+// fortsClearing начало вечернего клиринга срочного рынка: дальше идет следующий торговый день
+const fortsClearing = 19 * time.Hour
+
+// tradingDay торговый день, к которому относится время t (по московскому времени)
+// на срочном рынке вечерняя сессия (после клиринга 19:00) относится к следующему торговому дню
+// (вечер пятницы = понедельник), на остальных рынках торговый день = календарный
+func tradingDay(t time.Time, m Market) time.Time {
+	t = t.In(TzMsk)
+	day := Interval_D1.Truncate(t)
+	if m.Engine != "futures" || t.Sub(day) < fortsClearing {
+		return day
+	}
+	return nextWeekday(day)
+}
+
+// tradeDay торговый день сделки: дата сделки (TRADEDATE или SYSTIME),
+// сделка вечерней сессии срочного рынка (TRADINGSESSION = 2 или время после клиринга) = следующий торговый день
+func tradeDay(trade Trade, m Market) time.Time {
+	t := trade.Time()
+	if m.Engine == "futures" && trade.TradingSession == "2" {
+		return nextWeekday(Interval_D1.Truncate(t))
+	}
+	return tradingDay(t, m)
+}
+
+// nextWeekday следующий будний день
+func nextWeekday(day time.Time) time.Time {
+	day = day.AddDate(0, 0, 1)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// emit отправим сделки страницы по возрастанию номера (без повторов) и сохраним отметку
+func (s *tradeSubscription) emit(ctx context.Context, trades []Trade) error {
+	slices.SortFunc(trades, func(a, b Trade) int {
+		return cmp.Compare(a.TradeNo, b.TradeNo)
+	})
+	sent := false
+	for _, trade := range trades {
+		if trade.TradeNo <= s.mark.TradeNo {
+			continue
+		}
+		if s.session != "" && trade.TradingSession != "" && trade.TradingSession != s.session {
+			if !s.send(ctx, TradeEvent{Kind: TradeSessionChanged, Trade: trade}) {
+				return ctx.Err()
+			}
+		}
+		if !s.send(ctx, TradeEvent{Kind: TradeNew, Trade: trade}) {
+			return ctx.Err()
+		}
+		s.mark = TradeMark{TradeNo: trade.TradeNo, Time: trade.Time()}
+		if trade.TradingSession != "" {
+			s.session = trade.TradingSession
+		}
+		sent = true
+	}
+	if sent && s.wm != nil {
+		if err := s.wm.Save(s.key, s.mark); err != nil {
+			// отметка не сохранена, но сделки уже отправлены = лента продолжается
+			if !s.send(ctx, TradeEvent{Kind: TradeError, Err: err}) {
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// send отправим событие, false = подписка отменена
+func (s *tradeSubscription) send(ctx context.Context, event TradeEvent) bool {
+	select {
+	case s.ch <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
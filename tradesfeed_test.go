@@ -0,0 +1,235 @@
+package iss
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const feedPath = "engines/stock/markets/shares/boards/TQBR/securities/SBER/trades.json"
+
+// newFeed лента сделок SBER без горутины: poll вызывается из теста
+func newFeed(t *testing.T, f *fakeISS, wm TradeWatermark) (*tradeSubscription, chan TradeEvent) {
+	t.Helper()
+	ch := make(chan TradeEvent, 64)
+	sub := &tradeSubscription{
+		service:  newFakeClient(t, f).NewTradesService("stock", "shares", StockBoard, "SBER"),
+		key:      MarketShares.String() + "/SBER",
+		wm:       wm,
+		market:   MarketShares,
+		sessions: MarketSessions(MarketShares),
+		ch:       ch,
+	}
+	return sub, ch
+}
+
+// pollEvents один опрос ленты и полученные события
+func pollEvents(t *testing.T, sub *tradeSubscription, ch chan TradeEvent) []TradeEvent {
+	t.Helper()
+	if err := sub.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var events []TradeEvent
+	for len(ch) > 0 {
+		events = append(events, <-ch)
+	}
+	return events
+}
+
+// checkEvents события: номер сделки, SessionChanged = отрицательный номер
+func checkEvents(t *testing.T, events []TradeEvent, want ...int64) {
+	t.Helper()
+	got := make([]int64, 0, len(events))
+	for _, e := range events {
+		switch e.Kind {
+		case TradeNew:
+			got = append(got, e.Trade.TradeNo)
+		case TradeSessionChanged:
+			got = append(got, -e.Trade.TradeNo)
+		default:
+			t.Fatalf("событие %s: %v", e.Kind, e.Err)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("события %v, ожидали %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("события %v, ожидали %v", got, want)
+		}
+	}
+}
+
+// today дата сделок текущего торгового дня фондового рынка
+func today(t *testing.T) time.Time {
+	now := time.Now().In(TzMsk)
+	if now.Sub(Interval_D1.Truncate(now)) > 23*time.Hour+59*time.Minute {
+		t.Skip("конец дня: торговый день сменится во время теста")
+	}
+	return Interval_D1.Truncate(now)
+}
+
+// перезапуск: лента продолжается с сохраненной отметки без повторов
+func TestSubscribeTradesRestart(t *testing.T) {
+	day := today(t)
+	f := newFakeISS()
+	ft := &fakeTrades{count: 5, date: FormatDate(day)}
+	f.handle(feedPath, ft.handler)
+	path := filepath.Join(t.TempDir(), "marks.json")
+	wm, err := NewFileTradeWatermark(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, ch := newFeed(t, f, wm)
+	checkEvents(t, pollEvents(t, sub, ch), 1, 2, 3, 4, 5)
+	checkEvents(t, pollEvents(t, sub, ch))
+
+	// новый процесс: отметка из файла
+	ft.add(2)
+	wm, err = NewFileTradeWatermark(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mark, ok, _ := wm.Load(MarketShares.String() + "/SBER")
+	if !ok || mark.TradeNo != 5 {
+		t.Fatalf("отметка %+v %v", mark, ok)
+	}
+	sub, ch = newFeed(t, f, wm)
+	// первая страница дня (1 2 3) меньше отметки, но день тот же = нумерация не сменилась
+	checkEvents(t, pollEvents(t, sub, ch), 6, 7)
+	if q, _ := f.last(feedPath); q.Get("tradeno") != "7" {
+		t.Fatalf("параметры %v", q)
+	}
+}
+
+// отметка прошлого дня, нумерация начата заново = SessionChanged и лента с начала дня
+func TestSubscribeTradesStaleMark(t *testing.T) {
+	day := today(t)
+	f := newFakeISS()
+	ft := &fakeTrades{count: 4, date: FormatDate(day)}
+	f.handle(feedPath, ft.handler)
+	wm, err := NewFileTradeWatermark(filepath.Join(t.TempDir(), "marks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wm.Save(MarketShares.String()+"/SBER", TradeMark{TradeNo: 100, Time: day.Add(-6 * time.Hour)})
+
+	sub, ch := newFeed(t, f, wm)
+	checkEvents(t, pollEvents(t, sub, ch), -1, 1, 2, 3, 4)
+	if mark, _, _ := wm.Load(MarketShares.String() + "/SBER"); mark.TradeNo != 4 {
+		t.Fatalf("отметка %+v", mark)
+	}
+
+	// нумерация продолжается с прошлого дня = без SessionChanged
+	f = newFakeISS()
+	ft = &fakeTrades{count: 3, base: 100, date: FormatDate(day)}
+	f.handle(feedPath, ft.handler)
+	wm.Save(MarketShares.String()+"/SBER", TradeMark{TradeNo: 100, Time: day.Add(-6 * time.Hour)})
+	sub, ch = newFeed(t, f, wm)
+	checkEvents(t, pollEvents(t, sub, ch), 101, 102, 103)
+}
+
+// пустая первая страница (или сделки прошлого дня) = проверка нумерации повторяется,
+// новые сделки с меньшими номерами не теряются
+func TestSubscribeTradesEmptyFirstPage(t *testing.T) {
+	day := today(t)
+	f := newFakeISS()
+	ft := &fakeTrades{date: FormatDate(day)}
+	f.handle(feedPath, ft.handler)
+	wm, err := NewFileTradeWatermark(filepath.Join(t.TempDir(), "marks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wm.Save(MarketShares.String()+"/SBER", TradeMark{TradeNo: 100, Time: day.Add(-6 * time.Hour)})
+	sub, ch := newFeed(t, f, wm)
+
+	checkEvents(t, pollEvents(t, sub, ch))
+	if sub.checked {
+		t.Fatal("пустая страница: нумерация отмечена проверенной")
+	}
+
+	// сервер еще отдает сделки прошлого дня
+	ft.base, ft.count, ft.date = 97, 3, FormatDate(day.AddDate(0, 0, -1))
+	checkEvents(t, pollEvents(t, sub, ch))
+	if sub.checked {
+		t.Fatal("сделки прошлого дня: нумерация отмечена проверенной")
+	}
+
+	// первые сделки дня с новой нумерацией
+	ft.base, ft.count, ft.date = 0, 3, FormatDate(day)
+	checkEvents(t, pollEvents(t, sub, ch), -1, 1, 2, 3)
+	if !sub.checked {
+		t.Fatal("нумерация не проверена")
+	}
+	ft.add(1)
+	checkEvents(t, pollEvents(t, sub, ch), 4)
+}
+
+// без авторизации = одно событие TradeError с ErrUnauthorized и канал закрыт (без повторов)
+func TestSubscribeTradesUnauthorized(t *testing.T) {
+	f := newFakeISS()
+	f.granted = false
+	f.handle(feedPath, (&fakeTrades{count: 3}).handler)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ch := newFakeClient(t, f).SubscribeTrades(ctx, MarketShares, "SBER", nil)
+	event, ok := <-ch
+	if !ok || event.Kind != TradeError || !errors.Is(event.Err, ErrUnauthorized) {
+		t.Fatalf("событие %+v", event)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("канал не закрыт")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("лента остановлена по таймауту")
+	}
+	if n := f.count(feedPath); n != 1 {
+		t.Fatalf("запросов %d, ожидали 1", n)
+	}
+}
+
+func TestTradingDay(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation(layout, s, TzMsk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		name string
+		m    Market
+		t    string
+		want string
+	}{
+		{"фортс основная", MarketFutures, "2024-08-06 18:49:00", "2024-08-06"},
+		{"фортс вечерняя", MarketFutures, "2024-08-06 19:05:00", "2024-08-07"},
+		{"фортс вечер пятницы", MarketFutures, "2024-08-09 23:49:00", "2024-08-12"},
+		{"фортс после полуночи", MarketFutures, "2024-08-07 00:10:00", "2024-08-07"},
+		{"акции вечерняя", MarketShares, "2024-08-06 19:05:00", "2024-08-06"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDate(tradingDay(at(tt.t), tt.m)); got != tt.want {
+				t.Errorf("tradingDay %s", got)
+			}
+		})
+	}
+
+	// сделка вечерней сессии фортс по TRADINGSESSION (время не важно)
+	trade := Trade{TradeDate: "2024-08-09", TradeTime: "18:55:00", TradingSession: "2"}
+	if got := FormatDate(tradeDay(trade, MarketFutures)); got != "2024-08-12" {
+		t.Errorf("tradeDay вечерняя %s", got)
+	}
+	trade.TradingSession = "1"
+	if got := FormatDate(tradeDay(trade, MarketFutures)); got != "2024-08-09" {
+		t.Errorf("tradeDay основная %s", got)
+	}
+	if got := FormatDate(tradeDay(trade, MarketShares)); got != "2024-08-09" {
+		t.Errorf("tradeDay акции %s", got)
+	}
+}
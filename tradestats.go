@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 )
 
 /*
@@ -39,7 +41,7 @@ type TradeStats struct {
 	Volume     int64   `csv:"vol" json:"vol"`               // объем в лотах
 	Value      float64 `csv:"val" json:"val"`               // объем в рублях
 	Trades     int64   `csv:"trades" json:"trades"`         // количество сделок
-	Vwap       float64 `csv:"pr_vwap" json:pr_vwap"`        // взвешенная средняя цена
+	Vwap       float64 `csv:"pr_vwap" json:"pr_vwap"`       // взвешенная средняя цена
 	Change     float64 `csv:"pr_change" json:"pr_change"`   // изменение цены за период, %
 	TradesBuy  int64   `csv:"trades_b" json:"trades_b"`     // кол-во сделок на покупку
 	TradesSell int64   `csv:"trades_s" json:"trades_s"`     // кол-во сделок на продажу
@@ -60,7 +62,10 @@ type TradeStats struct {
 // TradeStatsService сервис для получения супер свечей (TradeStats)
 type TradeStatsService struct {
 	client     *Client
-	issRequest *IssRequest
+	issRequest *IssRequest  // не изменяемый
+	progress   ProgressFunc // прогресс выгрузки
+	mu         sync.Mutex
+	state      pageState // состояние постраничной выгрузки (последний блок data.cursor)
 }
 
 // NewTradeStatsService создание сервиса
@@ -84,6 +89,50 @@ func (c *Client) NewTradeStatsService(markets, symbol string, from, to string, d
 	}
 }
 
+// Csv запрашивать данные в формате csv (ответ меньше по размеру чем json)
+func (s *TradeStatsService) Csv() *TradeStatsService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issRequest = s.issRequest.Csv()
+	return s
+}
+
+// Progress установим функцию обратного вызова для отображения прогресса выгрузки
+func (s *TradeStatsService) Progress(fn ProgressFunc) *TradeStatsService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress = fn
+	return s
+}
+
+// Cursor вернем последний полученный курсор (false = если сервер его еще не прислал)
+func (s *TradeStatsService) Cursor() (Cursor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.cursor == nil {
+		return Cursor{}, false
+	}
+	return *s.state.cursor, true
+}
+
+// Reset начать выгрузку заново
+func (s *TradeStatsService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = pageState{}
+}
+
+// Clone независимая копия сервиса с начальным состоянием выгрузки
+func (s *TradeStatsService) Clone() *TradeStatsService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &TradeStatsService{
+		client:     s.client,
+		issRequest: s.issRequest,
+		progress:   s.progress,
+	}
+}
+
 // Next загружает следующую страницу данных
 // Если данных больше нет, то возвращается ошибка EOF
 // TODO что возвращать данные или ссылку?
@@ -91,15 +140,35 @@ func (s *TradeStatsService) Next() ([]TradeStats, error) {
 	var err error
 	const op = "TradeStatsService.Next"
 
+	// прогресс сообщаем после s.mu.Unlock (defer выполняются в обратном порядке):
+	// из ProgressFunc можно вызывать методы сервиса
+	var notify func()
+	defer func() {
+		if notify != nil {
+			notify()
+		}
+	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.issRequest.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// по курсору видно что данных больше нет = запрос не делаем
+	if s.state.done() {
+		return nil, EOF
+	}
+
 	r := &request{
 		method:  http.MethodGet,
-		fullURL: s.issRequest.URL(),
+		fullURL: s.issRequest.Start(s.state.start).URL(),
 	}
 
 	var resp Response
-	err = s.client.getJSON(r, &resp)
+	err = s.client.getData(r, &resp)
 	if err != nil {
-		slog.Error(op+".getJSON", "err", err.Error())
+		slog.Error(op+".getData", "err", err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -120,9 +189,13 @@ func (s *TradeStatsService) Next() ([]TradeStats, error) {
 	//	"maxdate", result[len(result)-1].Begin,
 	//)
 
-	// увеличим параметр start на кол-во полученных данных
-	s.issRequest.start += len(result)
-	s.client.log.Debug(op, "s.issRequest.start", s.issRequest.start)
+	cursor, ok := parseCursor(resp.DataCursor.Columns, resp.DataCursor.Data)
+	s.state.update(len(result), cursor, ok)
+	s.client.log.Debug(op, "start", s.state.start)
+	if progress := s.progress; progress != nil {
+		fetched, total := s.state.fetched, s.state.total()
+		notify = func() { progress(fetched, total) }
+	}
 
 	return result, nil
 }
@@ -131,13 +204,15 @@ func (s *TradeStatsService) Next() ([]TradeStats, error) {
 func (s *TradeStatsService) Do() ([]TradeStats, error) {
 	const op = "TradeStatsService.Do"
 
+	// отдельная копия = Do не мешает вызовам Next и другим Do
+	it := s.Clone()
 	result := make([]TradeStats, 0)
 	count := 1
 	for {
 		// "fetch candles: item(s) processed"
 		s.client.log.Debug(op, "запрос свечей: номер запроса", count)
 
-		t_result, err := s.Next()
+		t_result, err := it.Next()
 		if err != nil {
 			if errors.Is(err, EOF) {
 				break
@@ -153,37 +228,43 @@ func (s *TradeStatsService) Do() ([]TradeStats, error) {
 }
 
 // GetStockTradeStats получим данные TradeStats по заданной акции
-func (c *Client) GetStockTradeStats(symbol string, from, to string, latest bool) ([]TradeStats, error) {
-	service := c.NewTradeStatsService(AlgoPackStock, symbol, from, to, "", latest)
+func (c *Client) GetStockTradeStats(symbol string, from, to time.Time, latest bool) ([]TradeStats, error) {
+	service := c.NewTradeStatsService(AlgoPackStock, symbol, "", "", "", latest)
+	service.issRequest = service.issRequest.DateRange(from, to)
 	return service.Do()
 }
 
 // GetStockTradeStatsAll получим данные TradeStats по всем акция за заданный день
-func (c *Client) GetStockTradeStatsAll(date string, latest bool) ([]TradeStats, error) {
-	service := c.NewTradeStatsService(AlgoPackStock, "", "", "", date, latest)
+func (c *Client) GetStockTradeStatsAll(date time.Time, latest bool) ([]TradeStats, error) {
+	service := c.NewTradeStatsService(AlgoPackStock, "", "", "", "", latest)
+	service.issRequest = service.issRequest.OnDate(date)
 	return service.Do()
 }
 
 // GetFortsTradeStats получим данные TradeStats по заданному фьючерсу
-func (c *Client) GetFortsTradeStats(symbol string, from, to string, latest bool) ([]TradeStats, error) {
-	service := c.NewTradeStatsService(AlgoPackForts, symbol, from, to, "", latest)
+func (c *Client) GetFortsTradeStats(symbol string, from, to time.Time, latest bool) ([]TradeStats, error) {
+	service := c.NewTradeStatsService(AlgoPackForts, symbol, "", "", "", latest)
+	service.issRequest = service.issRequest.DateRange(from, to)
 	return service.Do()
 }
 
 // GetStockTradeStatsAll получим данные TradeStats по всем фьючерсам за заданный день
-func (c *Client) GetFortsTradeStatsAll(date string, latest bool) ([]TradeStats, error) {
-	service := c.NewTradeStatsService(AlgoPackForts, "", "", "", date, latest)
+func (c *Client) GetFortsTradeStatsAll(date time.Time, latest bool) ([]TradeStats, error) {
+	service := c.NewTradeStatsService(AlgoPackForts, "", "", "", "", latest)
+	service.issRequest = service.issRequest.OnDate(date)
 	return service.Do()
 }
 
 // GetFxTradeStats получим данные TradeStats по заданной валюте
-func (c *Client) GetFxTradeStats(symbol string, from, to string, latest bool) ([]TradeStats, error) {
-	service := c.NewTradeStatsService(AlgoPackFx, symbol, from, to, "", latest)
+func (c *Client) GetFxTradeStats(symbol string, from, to time.Time, latest bool) ([]TradeStats, error) {
+	service := c.NewTradeStatsService(AlgoPackFx, symbol, "", "", "", latest)
+	service.issRequest = service.issRequest.DateRange(from, to)
 	return service.Do()
 }
 
 // GetFxTradeStatsAll получим данные TradeStats по всем валютам за заданный день
-func (c *Client) GetFxTradeStatsAll(date string, latest bool) ([]TradeStats, error) {
-	service := c.NewTradeStatsService(AlgoPackFx, "", "", "", date, latest)
+func (c *Client) GetFxTradeStatsAll(date time.Time, latest bool) ([]TradeStats, error) {
+	service := c.NewTradeStatsService(AlgoPackFx, "", "", "", "", latest)
+	service.issRequest = service.issRequest.OnDate(date)
 	return service.Do()
 }
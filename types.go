@@ -12,30 +12,27 @@ func initMoscow() *time.Location {
 	return loc
 }
 
+// Table блок данных ответа iss: список колонок и строки данных
+// одинаковый для всех форматов (json, xml, csv)
+type Table struct {
+	Columns []string        `json:"columns"`
+	Data    [][]interface{} `json:"data"`
+}
+
 // Response структура ответа от iss
 type Response struct {
-	Candles struct {
-		Columns []string        `json:"columns"`
-		Data    [][]interface{} `json:"data"`
-	} `json:"candles"`
-	MarketData struct {
-		Columns []string        `json:"columns"`
-		Data    [][]interface{} `json:"data"`
-	} `json:"marketdata"`
-	Securities struct {
-		Columns []string        `json:"columns"`
-		Data    [][]interface{} `json:"data"`
-	} `json:"securities"`
-	OrderBook struct {
-		Columns []string        `json:"columns"`
-		Data    [][]interface{} `json:"data"`
-	} `json:"orderbook"`
-	History struct {
-		Columns []string        `json:"columns"`
-		Data    [][]interface{} `json:"data"`
-	} `json:"history"`
-	Data struct {
-		Columns []string        `json:"columns"`
-		Data    [][]interface{} `json:"data"`
-	} `json:"data"`
+	Candles    Table `json:"candles"`
+	MarketData Table `json:"marketdata"`
+	Securities Table `json:"securities"`
+	OrderBook  Table `json:"orderbook"`
+	Trades     Table `json:"trades"`
+	History    Table `json:"history"`
+	Data       Table `json:"data"`
+	Borders    Table `json:"borders"`
+	// блоки /iss/securities/{secid}
+	Description Table `json:"description"`
+	Boards      Table `json:"boards"`
+	// блоки постраничной выдачи
+	HistoryCursor Table `json:"history.cursor"`
+	DataCursor    Table `json:"data.cursor"`
 }
@@ -0,0 +1,269 @@
+package iss
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IssueKind тип проблемы в ряду свечей
+type IssueKind int
+
+const (
+	IssueOrder      IssueKind = iota // свеча раньше предыдущей
+	IssueDuplicate                   // повтор Begin
+	IssueOHLC                        // не согласованы цены: High < Low, Open/Close вне High..Low, цена <= 0
+	IssueZeroVolume                  // нулевой объем
+	IssueGap                         // пропущены свечи внутри торговой сессии
+)
+
+// String название проблемы
+func (k IssueKind) String() string {
+	switch k {
+	case IssueOrder:
+		return "order"
+	case IssueDuplicate:
+		return "duplicate"
+	case IssueOHLC:
+		return "ohlc"
+	case IssueZeroVolume:
+		return "zero_volume"
+	case IssueGap:
+		return "gap"
+	}
+	return "неизвестно"
+}
+
+// CandleIssue проблема в ряду свечей
+type CandleIssue struct {
+	Kind    IssueKind
+	Index   int    // номер свечи в Candles.Data
+	Begin   string // время начала свечи
+	Missing int    // IssueGap: сколько свечей пропущено перед этой
+	Message string
+}
+
+// CandleReport результат проверки ряда свечей
+type CandleReport struct {
+	Symbol   string
+	Interval string
+	Count    int // всего свечей
+	Missing  int // всего пропущено свечей внутри сессий
+	Issues   []CandleIssue
+}
+
+// OK проблем не найдено
+func (r CandleReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// CountOf количество проблем заданного типа
+func (r CandleReport) CountOf(kind IssueKind) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// String краткий итог проверки
+func (r CandleReport) String() string {
+	return fmt.Sprintf("%s %s: свечей %d, order %d, duplicate %d, ohlc %d, zero_volume %d, gap %d (пропущено %d)",
+		r.Symbol, r.Interval, r.Count,
+		r.CountOf(IssueOrder), r.CountOf(IssueDuplicate), r.CountOf(IssueOHLC),
+		r.CountOf(IssueZeroVolume), r.CountOf(IssueGap), r.Missing)
+}
+
+// MarketSessions расписание торговых сессий рынка (по московскому времени)
+func MarketSessions(m Market) []Session {
+	switch {
+	case m.Engine == "futures":
+		return []Session{
+			{Name: "main", Start: 9 * time.Hour, End: 14 * time.Hour},
+			{Name: "main", Start: 14*time.Hour + 5*time.Minute, End: 18*time.Hour + 50*time.Minute},
+			{Name: "evening", Start: 19*time.Hour + 5*time.Minute, End: 23*time.Hour + 50*time.Minute},
+		}
+	case m.Engine == "currency":
+		return []Session{
+			{Name: "main", Start: 7 * time.Hour, End: 19 * time.Hour},
+		}
+	case m.Market == "shares":
+		return []Session{
+			{Name: "morning", Start: 6*time.Hour + 50*time.Minute, End: 9*time.Hour + 50*time.Minute},
+			{Name: "main", Start: 9*time.Hour + 50*time.Minute, End: 18*time.Hour + 50*time.Minute},
+			{Name: "evening", Start: 19 * time.Hour, End: 23*time.Hour + 50*time.Minute},
+		}
+	case m.Market == "bonds", m.Market == "index":
+		return []Session{
+			{Name: "main", Start: 9*time.Hour + 50*time.Minute, End: 18*time.Hour + 50*time.Minute},
+		}
+	}
+	return DefaultSessions
+}
+
+// intradayDuration длительность внутридневной свечи по названию интервала (M1 M5 M10 H1 H4 ...)
+// 0 = не внутридневной интервал
+func intradayDuration(name string) time.Duration {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if len(name) < 2 || strings.HasPrefix(name, "MN") {
+		return 0
+	}
+	n, err := strconv.Atoi(name[1:])
+	if err != nil || n <= 0 {
+		return 0
+	}
+	switch name[0] {
+	case 'M':
+		return time.Duration(n) * time.Minute
+	case 'H':
+		return time.Duration(n) * time.Hour
+	}
+	return 0
+}
+
+// sessionOf сессия, в которую попадает время (false = вне сессий)
+func sessionOf(t time.Time, sessions []Session) (time.Time, Session, bool) {
+	day := Interval_D1.Truncate(t)
+	offset := t.Sub(day)
+	for _, s := range sessions {
+		if s.Contains(offset) {
+			return day, s, true
+		}
+	}
+	return day, Session{}, false
+}
+
+// missingBetween сколько свечей длиной d пропущено между prev и next внутри одной сессии
+func missingBetween(prev, next time.Time, d time.Duration, sessions []Session) int {
+	if d == 0 || !next.After(prev) {
+		return 0
+	}
+	prevDay, prevSession, ok1 := sessionOf(prev, sessions)
+	nextDay, nextSession, ok2 := sessionOf(next, sessions)
+	if !ok1 || !ok2 || !prevDay.Equal(nextDay) || prevSession != nextSession {
+		return 0
+	}
+	return max(int(next.Sub(prev)/d)-1, 0)
+}
+
+// Validate проверим ряд свечей: порядок, повторы, согласованность цен, нулевой объем
+// и пропуски внутри торговых сессий (только для внутридневных интервалов, пустой список сессий = DefaultSessions)
+// пропуски считаются только между соседними свечами одной сессии
+// (iss не присылает свечи за минуты без сделок, поэтому у неликвидных инструментов пропуски это норма)
+func (k Candles) Validate(sessions ...Session) CandleReport {
+	if len(sessions) == 0 {
+		sessions = DefaultSessions
+	}
+	report := CandleReport{Symbol: k.Symbol, Interval: k.Interval, Count: len(k.Data)}
+	add := func(kind IssueKind, i int, missing int, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, CandleIssue{
+			Kind:    kind,
+			Index:   i,
+			Begin:   k.Data[i].Begin,
+			Missing: missing,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	d := intradayDuration(k.Interval)
+	seen := make(map[string]int, len(k.Data))
+	for i, candle := range k.Data {
+		if first, ok := seen[candle.Begin]; ok {
+			add(IssueDuplicate, i, 0, "повтор свечи %d", first)
+		} else {
+			seen[candle.Begin] = i
+		}
+		if i > 0 {
+			prev := k.Data[i-1]
+			if candle.Begin < prev.Begin {
+				add(IssueOrder, i, 0, "раньше предыдущей свечи %s", prev.Begin)
+			} else if n := missingBetween(prev.Time(), candle.Time(), d, sessions); n > 0 {
+				report.Missing += n
+				add(IssueGap, i, n, "пропущено свечей: %d после %s", n, prev.Begin)
+			}
+		}
+		switch {
+		case candle.High < candle.Low:
+			add(IssueOHLC, i, 0, "High %v < Low %v", candle.High, candle.Low)
+		case candle.Open > candle.High || candle.Open < candle.Low:
+			add(IssueOHLC, i, 0, "Open %v вне диапазона %v..%v", candle.Open, candle.Low, candle.High)
+		case candle.Close > candle.High || candle.Close < candle.Low:
+			add(IssueOHLC, i, 0, "Close %v вне диапазона %v..%v", candle.Close, candle.Low, candle.High)
+		case candle.Low <= 0:
+			add(IssueOHLC, i, 0, "цена <= 0")
+		}
+		if candle.Volume == 0 {
+			add(IssueZeroVolume, i, 0, "нулевой объем")
+		}
+	}
+	return report
+}
+
+// RepairMode что исправлять в ряду свечей (можно комбинировать: RepairSort | RepairDedupe)
+type RepairMode int
+
+const (
+	RepairSort   RepairMode = 1 << iota // отсортировать по Begin
+	RepairDedupe                        // убрать повторы Begin (остается последняя полученная свеча)
+	RepairFill                          // заполнить пропуски внутри сессий ценой закрытия предыдущей свечи с нулевым объемом
+
+	RepairAll = RepairSort | RepairDedupe | RepairFill
+)
+
+// Repair исправленная копия ряда свечей
+// RepairFill работает только для внутридневных интервалов и отсортированных данных (вместе с RepairSort)
+// пустой список сессий = DefaultSessions
+func (k Candles) Repair(mode RepairMode, sessions ...Session) Candles {
+	if len(sessions) == 0 {
+		sessions = DefaultSessions
+	}
+	data := slices.Clone(k.Data)
+	if mode&RepairDedupe != 0 {
+		// на месте первой свечи с данным Begin оставим последнюю полученную
+		index := make(map[string]int, len(data))
+		unique := data[:0]
+		for _, candle := range data {
+			if i, ok := index[candle.Begin]; ok {
+				unique[i] = candle
+				continue
+			}
+			index[candle.Begin] = len(unique)
+			unique = append(unique, candle)
+		}
+		data = unique
+	}
+	if mode&RepairSort != 0 {
+		slices.SortStableFunc(data, func(a, b Candle) int {
+			return strings.Compare(a.Begin, b.Begin)
+		})
+	}
+
+	d := intradayDuration(k.Interval)
+	if mode&RepairFill != 0 && d > 0 {
+		filled := make([]Candle, 0, len(data))
+		for i, candle := range data {
+			if i > 0 {
+				prev := data[i-1]
+				t := prev.Time()
+				for n := missingBetween(t, candle.Time(), d, sessions); n > 0; n-- {
+					t = t.Add(d)
+					filled = append(filled, Candle{
+						Open:  prev.Close,
+						Close: prev.Close,
+						High:  prev.Close,
+						Low:   prev.Close,
+						Begin: t.Format(layout),
+						End:   t.Add(d - time.Second).Format(layout),
+					})
+				}
+			}
+			filled = append(filled, candle)
+		}
+		data = filled
+	}
+	return Candles{Symbol: k.Symbol, Interval: k.Interval, Data: data}
+}
@@ -0,0 +1,161 @@
+package iss
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// minuteCandle свеча M10 с началом begin, цены по close
+func minuteCandle(begin string, close float64) Candle {
+	t, _ := time.ParseInLocation(layout, begin, TzMsk)
+	return Candle{
+		Open: close, Close: close, High: close + 1, Low: close - 1, Value: 1e6, Volume: 1e3,
+		Begin: begin, End: t.Add(10*time.Minute - time.Second).Format(layout),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ohlc := func(open, close, high, low float64) Candle {
+		c := minuteCandle("2024-08-06 10:00:00", close)
+		c.Open, c.High, c.Low = open, high, low
+		return c
+	}
+	zero := minuteCandle("2024-08-06 10:00:00", 10)
+	zero.Volume = 0
+
+	shares := MarketSessions(MarketShares)
+	tests := []struct {
+		name     string
+		interval Interval
+		data     []Candle
+		sessions []Session
+		want     map[IssueKind]int
+		missing  int
+	}{
+		{"без проблем", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 10:00:00", 10), minuteCandle("2024-08-06 10:10:00", 11), minuteCandle("2024-08-06 10:20:00", 12),
+		}, nil, nil, 0},
+		{"повтор", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 10:00:00", 10), minuteCandle("2024-08-06 10:10:00", 11), minuteCandle("2024-08-06 10:10:00", 12),
+		}, nil, map[IssueKind]int{IssueDuplicate: 1}, 0},
+		{"не по порядку", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 10:10:00", 10), minuteCandle("2024-08-06 10:00:00", 11), minuteCandle("2024-08-06 10:20:00", 12),
+		}, nil, map[IssueKind]int{IssueOrder: 1, IssueGap: 1}, 1},
+		{"повтор не по порядку", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 10:10:00", 10), minuteCandle("2024-08-06 10:00:00", 11), minuteCandle("2024-08-06 10:10:00", 12),
+		}, nil, map[IssueKind]int{IssueOrder: 1, IssueDuplicate: 1}, 0},
+		{"Low > Open", Interval_M10, []Candle{ohlc(9, 10, 12, 9.5)}, nil, map[IssueKind]int{IssueOHLC: 1}, 0},
+		{"Low > Close", Interval_M10, []Candle{ohlc(10, 9, 12, 9.5)}, nil, map[IssueKind]int{IssueOHLC: 1}, 0},
+		{"High < Open", Interval_M10, []Candle{ohlc(13, 10, 12, 9)}, nil, map[IssueKind]int{IssueOHLC: 1}, 0},
+		{"High < Close", Interval_M10, []Candle{ohlc(10, 13, 12, 9)}, nil, map[IssueKind]int{IssueOHLC: 1}, 0},
+		{"High < Low", Interval_M10, []Candle{ohlc(10, 10, 9, 11)}, nil, map[IssueKind]int{IssueOHLC: 1}, 0},
+		{"нулевая цена", Interval_M10, []Candle{ohlc(0, 0, 0, 0)}, nil, map[IssueKind]int{IssueOHLC: 1}, 0},
+		{"нулевой объем", Interval_M10, []Candle{zero}, nil, map[IssueKind]int{IssueZeroVolume: 1}, 0},
+		{"пропуск внутри сессии", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 10:00:00", 10), minuteCandle("2024-08-06 10:40:00", 11),
+		}, shares, map[IssueKind]int{IssueGap: 1}, 3},
+		{"утренняя и основная сессии", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 09:40:00", 10), minuteCandle("2024-08-06 10:00:00", 11),
+		}, shares, nil, 0},
+		{"основная и вечерняя сессии", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 18:40:00", 12), minuteCandle("2024-08-06 19:10:00", 13),
+		}, shares, nil, 0},
+		{"ночь не пропуск", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 23:40:00", 10), minuteCandle("2024-08-07 06:50:00", 11),
+		}, shares, nil, 0},
+		{"выходные не пропуск", Interval_M10, []Candle{
+			minuteCandle("2024-08-09 23:40:00", 10), minuteCandle("2024-08-12 10:00:00", 11),
+		}, shares, nil, 0},
+		{"вне сессий не пропуск", Interval_M10, []Candle{
+			minuteCandle("2024-08-06 05:00:00", 10), minuteCandle("2024-08-06 06:00:00", 11),
+		}, shares, nil, 0},
+		{"дневные свечи без пропусков", Interval_D1, []Candle{
+			minuteCandle("2024-08-01 00:00:00", 10), minuteCandle("2024-08-06 00:00:00", 11),
+		}, nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := Candles{Symbol: "SBER", Interval: tt.interval.String(), Data: tt.data}
+			report := k.Validate(tt.sessions...)
+			for _, kind := range []IssueKind{IssueOrder, IssueDuplicate, IssueOHLC, IssueZeroVolume, IssueGap} {
+				if got := report.CountOf(kind); got != tt.want[kind] {
+					t.Errorf("%s: %d, ожидали %d (%+v)", kind, got, tt.want[kind], report.Issues)
+				}
+			}
+			if report.Missing != tt.missing || report.Count != len(tt.data) || report.OK() != (len(tt.want) == 0) {
+				t.Errorf("отчет %s", report)
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	k := Candles{Symbol: "SBER", Interval: Interval_M10.String(), Data: []Candle{
+		minuteCandle("2024-08-06 10:10:00", 11),
+		minuteCandle("2024-08-06 10:00:00", 10),
+		minuteCandle("2024-08-06 10:10:00", 12), // повтор: остается последняя полученная
+		minuteCandle("2024-08-06 10:40:00", 13),
+		minuteCandle("2024-08-06 23:40:00", 14),
+		minuteCandle("2024-08-07 06:50:00", 15), // ночь не заполняется
+	}}
+	begins := func(k Candles) string {
+		var b []string
+		for _, c := range k.Data {
+			b = append(b, c.Begin[11:16])
+		}
+		return strings.Join(b, " ")
+	}
+	sessions := MarketSessions(MarketShares)
+	tests := []struct {
+		name string
+		mode RepairMode
+		want string
+	}{
+		{"без исправлений", 0, "10:10 10:00 10:10 10:40 23:40 06:50"},
+		{"RepairSort", RepairSort, "10:00 10:10 10:10 10:40 23:40 06:50"},
+		{"RepairDedupe", RepairDedupe, "10:10 10:00 10:40 23:40 06:50"},
+		{"RepairSort|RepairDedupe", RepairSort | RepairDedupe, "10:00 10:10 10:40 23:40 06:50"},
+		{"RepairAll", RepairAll, "10:00 10:10 10:20 10:30 10:40 23:40 06:50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := k.Repair(tt.mode, sessions...)
+			if begins(got) != tt.want {
+				t.Fatalf("свечи %s, ожидали %s", begins(got), tt.want)
+			}
+			if got.Symbol != k.Symbol || got.Interval != k.Interval {
+				t.Errorf("ряд %s %s", got.Symbol, got.Interval)
+			}
+		})
+	}
+	if k.Data[0].Begin != "2024-08-06 10:10:00" || len(k.Data) != 6 {
+		t.Fatal("Repair изменил исходный ряд")
+	}
+
+	// повтор заменен последней полученной свечей на месте первой
+	repaired := k.Repair(RepairSort | RepairDedupe)
+	if repaired.Data[1].Close != 12 {
+		t.Errorf("повтор: close %v, ожидали 12", repaired.Data[1].Close)
+	}
+	// заполненные свечи = close предыдущей без объема
+	filled := k.Repair(RepairAll, sessions...)
+	for _, c := range filled.Data[2:4] {
+		if c.Open != 12 || c.Close != 12 || c.High != 12 || c.Low != 12 || c.Volume != 0 || c.Value != 0 {
+			t.Errorf("заполненная свеча %+v", c)
+		}
+	}
+	if filled.Data[2].End != "2024-08-06 10:29:59" {
+		t.Errorf("End заполненной свечи %s", filled.Data[2].End)
+	}
+	if report := filled.Validate(sessions...); report.CountOf(IssueGap) != 0 || report.CountOf(IssueOrder) != 0 || report.CountOf(IssueDuplicate) != 0 {
+		t.Errorf("после RepairAll: %s", report)
+	}
+	// для дневных свечей пропуски не заполняются
+	daily := Candles{Interval: Interval_D1.String(), Data: []Candle{
+		minuteCandle("2024-08-01 00:00:00", 10), minuteCandle("2024-08-06 00:00:00", 11),
+	}}
+	if got := daily.Repair(RepairAll); len(got.Data) != 2 {
+		t.Errorf("дневные свечи: %d", len(got.Data))
+	}
+}